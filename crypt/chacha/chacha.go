@@ -0,0 +1,109 @@
+package chacha
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+)
+
+var ErrCiphertext = errors.New("chacha: ciphertext too short")
+
+// ChaCha ChaCha20-Poly1305认证加密,无AES-NI的环境下比AES-GCM更快.
+// 每次加密生成随机nonce并拼接在密文之前: nonce|ciphertext|tag
+type ChaCha struct {
+	key    []byte
+	aad    []byte
+	x      bool
+	coding crypt.IEncoding
+	rand   io.Reader
+}
+
+type opt func(o *ChaCha)
+
+// WithKey 长度必须为32
+func WithKey(key string) opt {
+	return func(o *ChaCha) {
+		o.key = []byte(key)
+	}
+}
+
+// WithAdditionalData 附加认证数据,参与校验但不加密,加解密两端需一致
+func WithAdditionalData(aad string) opt {
+	return func(o *ChaCha) {
+		o.aad = []byte(aad)
+	}
+}
+
+// WithXChaCha 使用XChaCha20-Poly1305,nonce为24字节,随机生成也不必担心碰撞
+func WithXChaCha() opt {
+	return func(o *ChaCha) {
+		o.x = true
+	}
+}
+func WithEncoding(coding crypt.IEncoding) opt {
+	return func(o *ChaCha) {
+		o.coding = coding
+	}
+}
+
+// WithRand nonce的随机源,默认crypto/rand,仅测试时替换
+func WithRand(r io.Reader) opt {
+	return func(o *ChaCha) {
+		o.rand = r
+	}
+}
+
+func New(opts ...opt) (os *ChaCha) {
+	os = &ChaCha{
+		coding: std.New(),
+		rand:   rand.Reader,
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *ChaCha) Encrypt(origin []byte) (encrypt string, err error) {
+	var aead cipher.AEAD
+	if aead, err = o.aead(); err != nil {
+		return
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(origin)+aead.Overhead())
+	if _, err = io.ReadFull(o.rand, nonce); err != nil {
+		return
+	}
+	encrypt = o.coding.Encode(aead.Seal(nonce, nonce, origin, o.aad))
+	return
+}
+
+func (o *ChaCha) Decrypt(encrypt string) (origin []byte, err error) {
+	var aead cipher.AEAD
+	if aead, err = o.aead(); err != nil {
+		return
+	}
+
+	var en []byte
+	if en, err = o.coding.Decode(encrypt); err != nil {
+		return
+	}
+	if len(en) < aead.NonceSize()+aead.Overhead() {
+		err = ErrCiphertext
+		return
+	}
+	return aead.Open(nil, en[:aead.NonceSize()], en[aead.NonceSize():], o.aad)
+}
+
+func (o *ChaCha) aead() (cipher.AEAD, error) {
+	if o.x {
+		return chacha20poly1305.NewX(o.key)
+	}
+	return chacha20poly1305.New(o.key)
+}
@@ -0,0 +1,65 @@
+package chacha
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	kaKey, _ = hex.DecodeString("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	kaAad, _ = hex.DecodeString("50515253c0c1c2c3c4c5c6c7")
+	kaPt     = "Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it."
+)
+
+// RFC 8439, 2.8.2
+func TestKnownAnswer(t *testing.T) {
+	nonce, _ := hex.DecodeString("070000004041424344454647")
+	ct, _ := hex.DecodeString("d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d63dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b3692ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc3ff4def08e4b7a9de576d26586cec64b6116" + "1ae10b594f09e26a7e902ecbd0600691")
+
+	assertKnownAnswer(t, New(
+		WithKey(string(kaKey)),
+		WithAdditionalData(string(kaAad)),
+		WithRand(bytes.NewReader(nonce)),
+	), nonce, ct)
+}
+
+// draft-irtf-cfrg-xchacha-03, A.3.1
+func TestKnownAnswerX(t *testing.T) {
+	nonce, _ := hex.DecodeString("404142434445464748494a4b4c4d4e4f5051525354555657")
+	ct, _ := hex.DecodeString("bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b4522f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff921f9664c97637da9768812f615c68b13b52e" + "c0875924c1c7987947deafd8780acf49")
+
+	assertKnownAnswer(t, New(
+		WithKey(string(kaKey)),
+		WithAdditionalData(string(kaAad)),
+		WithXChaCha(),
+		WithRand(bytes.NewReader(nonce)),
+	), nonce, ct)
+}
+
+func assertKnownAnswer(t *testing.T, cr *ChaCha, nonce, ct []byte) {
+	en, err := cr.Encrypt([]byte(kaPt))
+	if err != nil {
+		t.Fatalf("Encrypt: %+v", err)
+	}
+	raw, _ := cr.coding.Decode(en)
+	if !bytes.Equal(raw, append(nonce, ct...)) {
+		t.Errorf("ciphertext:\t%x", raw)
+	}
+
+	origin, err := cr.Decrypt(en)
+	if err != nil || string(origin) != kaPt {
+		t.Errorf("origin, err:\t%+v,%+v", string(origin), err)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	cr := New(WithKey(string(kaKey)), WithXChaCha())
+	en, _ := cr.Encrypt([]byte(kaPt))
+
+	raw, _ := cr.coding.Decode(en)
+	raw[len(raw)-1] ^= 1
+	if _, err := cr.Decrypt(cr.coding.Encode(raw)); err == nil {
+		t.Errorf("tampered ciphertext is accepted")
+	}
+}
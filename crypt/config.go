@@ -0,0 +1,120 @@
+package crypt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("crypt: unknown algorithm")
+	ErrEmptyKey         = errors.New("crypt: key is empty")
+)
+
+// Key 密钥来源,按Inline、Env、File的顺序取第一个非空项
+type Key struct {
+	// Inline 直接写在配置中
+	Inline string `json:"inline" yaml:"inline"`
+	// Env 环境变量名
+	Env string `json:"env" yaml:"env"`
+	// File 文件路径,末尾的换行会被去掉
+	File string `json:"file" yaml:"file"`
+}
+
+func (k Key) IsZero() bool {
+	return k.Inline == "" && k.Env == "" && k.File == ""
+}
+
+func (k Key) Load() (key string, err error) {
+	switch {
+	case k.Inline != "":
+		key = k.Inline
+	case k.Env != "":
+		if key = os.Getenv(k.Env); key == "" {
+			err = fmt.Errorf("%w: env %s is not set", ErrEmptyKey, k.Env)
+		}
+	case k.File != "":
+		var b []byte
+		if b, err = os.ReadFile(k.File); err != nil {
+			err = fmt.Errorf("crypt: read key file: %w", err)
+			return
+		}
+		if key = strings.TrimRight(string(b), "\r\n"); key == "" {
+			err = fmt.Errorf("%w: file %s is empty", ErrEmptyKey, k.File)
+		}
+	default:
+		err = ErrEmptyKey
+	}
+	return
+}
+
+// Config 声明式的加密配置,由各算法包注册的Builder解析
+type Config struct {
+	// Algorithm 算法名,如cbc/ecb/gcm/rsa,需import对应的包完成注册
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+	// Key 对称密钥
+	Key Key `json:"key" yaml:"key"`
+	// PublicKey、PrivateKey 非对称密钥,PEM格式
+	PublicKey  Key `json:"public_key" yaml:"public_key"`
+	PrivateKey Key `json:"private_key" yaml:"private_key"`
+
+	Iv string `json:"iv" yaml:"iv"`
+	// RandomIv 每次加密生成随机iv并随密文传递,开启后忽略Iv
+	RandomIv bool `json:"random_iv" yaml:"random_iv"`
+	// MacKey 非空时对密文追加HMAC-SHA256
+	MacKey Key `json:"mac_key" yaml:"mac_key"`
+
+	Padding        string `json:"padding" yaml:"padding"`
+	AdditionalData string `json:"additional_data" yaml:"additional_data"`
+	// Encoding 密文编码名,见crypt/encoding,默认std
+	Encoding string `json:"encoding" yaml:"encoding"`
+}
+
+// PaddingOr 校验配置的填充方式,未配置时返回def,供各算法的Builder使用
+func (c *Config) PaddingOr(def string, supported ...string) (padding string, err error) {
+	if c.Padding == "" {
+		padding = def
+		return
+	}
+	for _, p := range supported {
+		if c.Padding == p {
+			padding = p
+			return
+		}
+	}
+	err = fmt.Errorf("unsupported padding %q", c.Padding)
+	return
+}
+
+// Builder 根据配置构造ICrypt,配置有误时应在此返回错误而不是等到加解密时
+type Builder func(conf *Config) (cr ICrypt, err error)
+
+var (
+	builderLock sync.RWMutex
+	builders    = make(map[string]Builder)
+)
+
+// Register 注册算法,一般在算法包的init中调用
+func Register(algorithm string, b Builder) {
+	builderLock.Lock()
+	defer builderLock.Unlock()
+	builders[algorithm] = b
+}
+
+// New 按配置构造ICrypt
+func New(conf *Config) (cr ICrypt, err error) {
+	builderLock.RLock()
+	b, ok := builders[conf.Algorithm]
+	builderLock.RUnlock()
+	if !ok {
+		err = fmt.Errorf("%w: %q, is its package imported?", ErrUnknownAlgorithm, conf.Algorithm)
+		return
+	}
+
+	if cr, err = b(conf); err != nil {
+		err = fmt.Errorf("crypt: %s: %w", conf.Algorithm, err)
+	}
+	return
+}
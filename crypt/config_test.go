@@ -0,0 +1,83 @@
+package crypt_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/neo532/kratos_kit/crypt"
+	_ "github.com/neo532/kratos_kit/crypt/openssl/cbc"
+	_ "github.com/neo532/kratos_kit/crypt/openssl/ecb"
+	_ "github.com/neo532/kratos_kit/crypt/openssl/gcm"
+	_ "github.com/neo532/kratos_kit/crypt/openssl/rsa"
+)
+
+const (
+	cnt  = "abcdefghijklmnopqrstuvwxyz1234567890------------"
+	keyM = "rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("CRYPT_TEST_KEY", keyM)
+	file := filepath.Join(t.TempDir(), "key")
+	os.WriteFile(file, []byte(keyM+"\n"), 0600)
+
+	priv, _ := rsa.GenerateKey(rand.Reader, 1024)
+	privPem := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	pubPem := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&priv.PublicKey)}))
+
+	for _, conf := range []*crypt.Config{
+		{Algorithm: "cbc", Key: crypt.Key{Inline: keyM}, Iv: "Kq0%4bX!z8Vd3@Lm", Encoding: "hex"},
+		{Algorithm: "cbc", Key: crypt.Key{Inline: keyM}, RandomIv: true, MacKey: crypt.Key{Inline: "mac-key"}},
+		{Algorithm: "ecb", Key: crypt.Key{Env: "CRYPT_TEST_KEY"}, Padding: "PKCS5"},
+		{Algorithm: "gcm", Key: crypt.Key{File: file}, AdditionalData: "uid:1", Encoding: "rawurl"},
+		{Algorithm: "rsa", PublicKey: crypt.Key{Inline: pubPem}, PrivateKey: crypt.Key{Inline: privPem}, Padding: "OAEP"},
+	} {
+		cr, err := crypt.New(conf)
+		if err != nil {
+			t.Fatalf("%s New: %+v", conf.Algorithm, err)
+		}
+		en, err := cr.Encrypt([]byte(cnt))
+		if err != nil {
+			t.Fatalf("%s Encrypt: %+v", conf.Algorithm, err)
+		}
+		origin, err := cr.Decrypt(en)
+		if err != nil || string(origin) != cnt {
+			t.Errorf("%s origin, err:\t%+v,%+v", conf.Algorithm, string(origin), err)
+		}
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for name, conf := range map[string]*crypt.Config{
+		"algorithm": {Algorithm: "des", Key: crypt.Key{Inline: keyM}},
+		"key":       {Algorithm: "gcm"},
+		"env":       {Algorithm: "gcm", Key: crypt.Key{Env: "CRYPT_TEST_KEY_UNSET"}},
+		"file":      {Algorithm: "gcm", Key: crypt.Key{File: "/nonexistent/key"}},
+		"keyLength": {Algorithm: "ecb", Key: crypt.Key{Inline: "short"}},
+		"iv":        {Algorithm: "cbc", Key: crypt.Key{Inline: keyM}, Iv: "short"},
+		"padding":   {Algorithm: "ecb", Key: crypt.Key{Inline: keyM}, Padding: "ISO10126"},
+		"encoding":  {Algorithm: "gcm", Key: crypt.Key{Inline: keyM}, Encoding: "base64"},
+		"cbcPad":    {Algorithm: "cbc", Key: crypt.Key{Inline: keyM}, RandomIv: true, Padding: "OAEP"},
+		"rsaPad":    {Algorithm: "rsa", PublicKey: crypt.Key{Inline: "not a pem"}, Padding: "PKCS7"},
+		"rsaCoding": {Algorithm: "rsa", PublicKey: crypt.Key{Inline: "not a pem"}, Encoding: "base64"},
+		"rsaKey":    {Algorithm: "rsa"},
+		"rsaPem":    {Algorithm: "rsa", PublicKey: crypt.Key{Inline: "not a pem"}},
+	} {
+		if _, err := crypt.New(conf); err == nil {
+			t.Errorf("%s: invalid config is accepted", name)
+		} else {
+			t.Logf("%s: %v", name, err)
+		}
+	}
+
+	_, err := crypt.New(&crypt.Config{Algorithm: "des"})
+	if !errors.Is(err, crypt.ErrUnknownAlgorithm) {
+		t.Errorf("unknown algorithm: %+v", err)
+	}
+}
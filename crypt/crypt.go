@@ -1,5 +1,9 @@
 package crypt
 
+import (
+	"io"
+)
+
 type ICrypt interface {
 	Encrypt(origin []byte) (encrpy string, err error)
 	Decrypt(encrpy string) (origin []byte, err error)
@@ -9,3 +13,14 @@ type IEncoding interface {
 	Encode(origin []byte) (code string)
 	Decode(code string) (origin []byte, err error)
 }
+
+type ISigner interface {
+	Sign(origin []byte) (sign string, err error)
+	Verify(origin []byte, sign string) (err error)
+}
+
+// IStream 流式加解密,内存占用与数据大小无关,密文为二进制不经过IEncoding
+type IStream interface {
+	EncryptStream(dst io.Writer, src io.Reader) (err error)
+	DecryptStream(dst io.Writer, src io.Reader) (err error)
+}
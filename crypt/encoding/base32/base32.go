@@ -0,0 +1,21 @@
+package base32
+
+import (
+	"encoding/base32"
+)
+
+// Base32 RFC 4648标准字母表,带填充
+type Base32 struct {
+}
+
+func New() *Base32 {
+	return &Base32{}
+}
+
+func (o *Base32) Encode(origin []byte) (code string) {
+	return base32.StdEncoding.EncodeToString(origin)
+}
+
+func (o *Base32) Decode(code string) (origin []byte, err error) {
+	return base32.StdEncoding.DecodeString(code)
+}
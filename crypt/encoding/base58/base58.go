@@ -0,0 +1,92 @@
+package base58
+
+import (
+	"errors"
+)
+
+// alphabet Bitcoin字母表,去掉了易混淆的0OIl
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var ErrInvalidChar = errors.New("base58: invalid character")
+
+var decodeMap [256]int8
+
+func init() {
+	for i := range decodeMap {
+		decodeMap[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		decodeMap[alphabet[i]] = int8(i)
+	}
+}
+
+type Base58 struct {
+}
+
+func New() *Base58 {
+	return &Base58{}
+}
+
+func (o *Base58) Encode(origin []byte) (code string) {
+	// 前导0x00各对应一个'1'
+	zeros := 0
+	for zeros < len(origin) && origin[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256)/log(58) ≈ 1.37
+	buf := make([]byte, (len(origin)-zeros)*138/100+1)
+	size := 0
+	for _, b := range origin[zeros:] {
+		carry := int(b)
+		for i := 0; i < size || carry != 0; i++ {
+			carry += 256 * int(buf[i])
+			buf[i] = byte(carry % 58)
+			carry /= 58
+			if i >= size {
+				size = i + 1
+			}
+		}
+	}
+
+	rst := make([]byte, zeros+size)
+	for i := 0; i < zeros; i++ {
+		rst[i] = alphabet[0]
+	}
+	for i := 0; i < size; i++ {
+		rst[zeros+i] = alphabet[buf[size-1-i]]
+	}
+	return string(rst)
+}
+
+func (o *Base58) Decode(code string) (origin []byte, err error) {
+	zeros := 0
+	for zeros < len(code) && code[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	// log(58)/log(256) ≈ 0.733
+	buf := make([]byte, (len(code)-zeros)*733/1000+1)
+	size := 0
+	for i := zeros; i < len(code); i++ {
+		carry := int(decodeMap[code[i]])
+		if carry < 0 {
+			err = ErrInvalidChar
+			return
+		}
+		for j := 0; j < size || carry != 0; j++ {
+			carry += 58 * int(buf[j])
+			buf[j] = byte(carry)
+			carry >>= 8
+			if j >= size {
+				size = j + 1
+			}
+		}
+	}
+
+	origin = make([]byte, zeros+size)
+	for i := 0; i < size; i++ {
+		origin[zeros+i] = buf[size-1-i]
+	}
+	return
+}
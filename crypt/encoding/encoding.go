@@ -0,0 +1,66 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/base32"
+	"github.com/neo532/kratos_kit/crypt/encoding/base58"
+	"github.com/neo532/kratos_kit/crypt/encoding/hex"
+	"github.com/neo532/kratos_kit/crypt/encoding/rawurl"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+	"github.com/neo532/kratos_kit/crypt/encoding/url"
+)
+
+var ErrUnknown = errors.New("encoding: unknown encoding")
+
+const (
+	Std    = "std"
+	Url    = "url"
+	RawUrl = "rawurl"
+	Hex    = "hex"
+	Base32 = "base32"
+	Base58 = "base58"
+)
+
+var (
+	lock     sync.RWMutex
+	registry = map[string]crypt.IEncoding{
+		Std:    std.New(),
+		Url:    url.New(),
+		RawUrl: rawurl.New(),
+		Hex:    hex.New(),
+		Base32: base32.New(),
+		Base58: base58.New(),
+	}
+)
+
+// Register 注册自定义编码,同名覆盖
+func Register(name string, coding crypt.IEncoding) {
+	lock.Lock()
+	defer lock.Unlock()
+	registry[name] = coding
+}
+
+// Get 按名称获取编码,供配置中以字符串指定编码
+func Get(name string) (coding crypt.IEncoding, err error) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	var ok bool
+	if coding, ok = registry[name]; !ok {
+		err = fmt.Errorf("%w: %s", ErrUnknown, name)
+	}
+	return
+}
+
+// FromConfig 配置中的密文编码,未配置时为std,供各算法的Builder使用
+func FromConfig(conf *crypt.Config) (coding crypt.IEncoding, err error) {
+	if conf.Encoding == "" {
+		coding = std.New()
+		return
+	}
+	return Get(conf.Encoding)
+}
@@ -0,0 +1,45 @@
+package encoding
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	cnt := []byte("\x00\x00abcdefghijklmnopqrstuvwxyz1234567890?>\xff")
+	for _, name := range []string{Std, Url, RawUrl, Hex, Base32, Base58} {
+		coding, err := Get(name)
+		if err != nil {
+			t.Fatalf("Get %s: %+v", name, err)
+		}
+		origin, err := coding.Decode(coding.Encode(cnt))
+		if err != nil || string(origin) != string(cnt) {
+			t.Errorf("%s origin, err:\t%q,%+v", name, origin, err)
+		}
+	}
+
+	if _, err := Get("base64"); !errors.Is(err, ErrUnknown) {
+		t.Errorf("unknown encoding: %+v", err)
+	}
+}
+
+func TestKnownAnswer(t *testing.T) {
+	for _, c := range []struct {
+		name, origin, code string
+	}{
+		{Hex, "\x01\xab\xff", "01abff"},
+		{RawUrl, "\xfb\xff", "-_8"},
+		{Base32, "foobar", "MZXW6YTBOI======"},
+		{Base58, "Hello World!", "2NEpo7TZRRrLZSi2U"},
+		{Base58, "\x00\x00\x28\x7f\xb4\xcd", "11233QC4"},
+		{Base58, "", ""},
+	} {
+		coding, _ := Get(c.name)
+		if code := coding.Encode([]byte(c.origin)); code != c.code {
+			t.Errorf("%s Encode(%q):\t%s", c.name, c.origin, code)
+		}
+		if origin, err := coding.Decode(c.code); err != nil || string(origin) != c.origin {
+			t.Errorf("%s Decode(%s):\t%q,%+v", c.name, c.code, origin, err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package hex
+
+import (
+	"encoding/hex"
+)
+
+type Hex struct {
+}
+
+func New() *Hex {
+	return &Hex{}
+}
+
+// Encode 小写输出,Decode大小写均可
+func (o *Hex) Encode(origin []byte) (code string) {
+	return hex.EncodeToString(origin)
+}
+
+func (o *Hex) Decode(code string) (origin []byte, err error) {
+	return hex.DecodeString(code)
+}
@@ -0,0 +1,21 @@
+package rawurl
+
+import (
+	"encoding/base64"
+)
+
+// RawUrl URL安全且无填充的base64,JWT等场景使用
+type RawUrl struct {
+}
+
+func New() *RawUrl {
+	return &RawUrl{}
+}
+
+func (o *RawUrl) Encode(origin []byte) (code string) {
+	return base64.RawURLEncoding.EncodeToString(origin)
+}
+
+func (o *RawUrl) Decode(code string) (origin []byte, err error) {
+	return base64.RawURLEncoding.DecodeString(code)
+}
@@ -0,0 +1,154 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+)
+
+// 信封格式: version(1) | len(wrapped)(2,大端) | wrapped | nonce | ciphertext | tag
+const version byte = 1
+
+var (
+	ErrNoProvider = errors.New("envelope: no key provider")
+	ErrFormat     = errors.New("envelope: malformed envelope")
+	ErrVersion    = errors.New("envelope: unsupported version")
+)
+
+// KeyProvider 数据密钥(DEK)的生成和解封,主密钥不离开provider,可对接各家KMS
+type KeyProvider interface {
+	// GenerateDataKey 生成一个数据密钥,返回明文和被主密钥加密后的密文
+	GenerateDataKey() (plaintext, wrapped []byte, err error)
+	// UnwrapDataKey 用主密钥解出数据密钥的明文
+	UnwrapDataKey(wrapped []byte) (plaintext []byte, err error)
+}
+
+// Envelope 信封加密:每次加密向provider申请新的数据密钥,用AES-GCM加密数据,
+// 被封装的数据密钥随密文一起保存,解密时再交给provider解封.
+type Envelope struct {
+	provider KeyProvider
+	aad      []byte
+	coding   crypt.IEncoding
+	rand     io.Reader
+}
+
+type opt func(o *Envelope)
+
+func WithProvider(p KeyProvider) opt {
+	return func(o *Envelope) {
+		o.provider = p
+	}
+}
+
+// WithAdditionalData 附加认证数据,参与校验但不加密,加解密两端需一致
+func WithAdditionalData(aad string) opt {
+	return func(o *Envelope) {
+		o.aad = []byte(aad)
+	}
+}
+func WithEncoding(coding crypt.IEncoding) opt {
+	return func(o *Envelope) {
+		o.coding = coding
+	}
+}
+
+func New(opts ...opt) (os *Envelope) {
+	os = &Envelope{
+		coding: std.New(),
+		rand:   rand.Reader,
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *Envelope) Encrypt(origin []byte) (encrypt string, err error) {
+	if o.provider == nil {
+		err = ErrNoProvider
+		return
+	}
+
+	var dek, wrapped []byte
+	if dek, wrapped, err = o.provider.GenerateDataKey(); err != nil {
+		return
+	}
+	if len(wrapped) > 0xffff {
+		err = ErrFormat
+		return
+	}
+
+	var aead cipher.AEAD
+	if aead, err = newAEAD(dek); err != nil {
+		return
+	}
+
+	buf := make([]byte, 3, 3+len(wrapped)+aead.NonceSize()+len(origin)+aead.Overhead())
+	buf[0] = version
+	binary.BigEndian.PutUint16(buf[1:3], uint16(len(wrapped)))
+	buf = append(buf, wrapped...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(o.rand, nonce); err != nil {
+		return
+	}
+	buf = append(buf, nonce...)
+
+	encrypt = o.coding.Encode(aead.Seal(buf, nonce, origin, o.aad))
+	return
+}
+
+func (o *Envelope) Decrypt(encrypt string) (origin []byte, err error) {
+	if o.provider == nil {
+		err = ErrNoProvider
+		return
+	}
+
+	var en []byte
+	if en, err = o.coding.Decode(encrypt); err != nil {
+		return
+	}
+	if len(en) < 3 {
+		err = ErrFormat
+		return
+	}
+	if en[0] != version {
+		err = ErrVersion
+		return
+	}
+	n := int(binary.BigEndian.Uint16(en[1:3]))
+	if len(en) < 3+n {
+		err = ErrFormat
+		return
+	}
+
+	var dek []byte
+	if dek, err = o.provider.UnwrapDataKey(en[3 : 3+n]); err != nil {
+		return
+	}
+	var aead cipher.AEAD
+	if aead, err = newAEAD(dek); err != nil {
+		return
+	}
+
+	body := en[3+n:]
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		err = ErrFormat
+		return
+	}
+	return aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], o.aad)
+}
+
+func newAEAD(key []byte) (aead cipher.AEAD, err error) {
+	var block cipher.Block
+	if block, err = aes.NewCipher(key); err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,73 @@
+package envelope
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/hex"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+)
+
+var cnt = "abcdefghijklmnopqrstuvwxyz1234567890------------"
+
+func TestEnvelope(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "master.key")
+	if err := os.WriteFile(file, []byte("000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewFile(file, hex.New())
+	if err != nil {
+		t.Fatalf("NewFile: %+v", err)
+	}
+
+	cr := New(WithProvider(p), WithAdditionalData("uid:1"))
+	en, err := cr.Encrypt([]byte(cnt))
+	if err != nil {
+		t.Fatalf("Encrypt: %+v", err)
+	}
+	origin, err := cr.Decrypt(en)
+	if err != nil || string(origin) != cnt {
+		t.Errorf("origin, err:\t%+v,%+v", string(origin), err)
+	}
+
+	other, _ := NewLocal([]byte("fedcba9876543210fedcba9876543210"))
+	if _, err = New(WithProvider(other), WithAdditionalData("uid:1")).Decrypt(en); err == nil {
+		t.Errorf("Decrypt with another master key")
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		in     string
+		coding crypt.IEncoding
+		key    []byte
+	}{
+		{"hex128", "000102030405060708090a0b0c0d0e0f\n", hex.New(), []byte("\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f")},
+		{"base64_128", "AAECAwQFBgcICQoLDA0ODw==", std.New(), []byte("\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f")},
+		// 既是合法的原始密钥也是合法的base64,按指定的格式解析
+		{"raw256", "Abcdefghijklmnopqrstuvwxyz012345", nil, []byte("Abcdefghijklmnopqrstuvwxyz012345")},
+	} {
+		file := filepath.Join(t.TempDir(), "master.key")
+		if err := os.WriteFile(file, []byte(c.in), 0600); err != nil {
+			t.Fatal(err)
+		}
+		p, err := NewFile(file, c.coding)
+		if err != nil {
+			t.Fatalf("%s NewFile: %+v", c.name, err)
+		}
+		want, _ := NewLocal(c.key)
+		_, wrapped, _ := p.GenerateDataKey()
+		if _, err = want.UnwrapDataKey(wrapped); err != nil {
+			t.Errorf("%s is not parsed as the expected key: %+v", c.name, err)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "master.key")
+	os.WriteFile(file, []byte("Abcdefghijklmnopqrstuvwxyz012345"), 0600)
+	if _, err := NewFile(file, hex.New()); err == nil {
+		t.Errorf("NewFile with a wrong format")
+	}
+}
@@ -0,0 +1,77 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/neo532/kratos_kit/crypt"
+)
+
+// 数据密钥长度,即AES-256
+const dataKeySize = 32
+
+var ErrMasterKey = errors.New("envelope: master key must be 16, 24 or 32 bytes")
+
+// Local 本地主密钥的KeyProvider,用AES-GCM封装数据密钥,用于测试和无KMS的环境
+type Local struct {
+	aead cipher.AEAD
+}
+
+func NewLocal(masterKey []byte) (p *Local, err error) {
+	if !validKey(masterKey) {
+		err = ErrMasterKey
+		return
+	}
+	p = &Local{}
+	p.aead, err = newAEAD(masterKey)
+	return
+}
+
+// NewFile 从文件读取主密钥,coding为文件内容的编码,如hex.New()、std.New(),
+// 为nil时文件内容即原始密钥.主密钥的格式不做猜测,32个字母数字既是合法的原始密钥也是合法的base64
+func NewFile(file string, coding crypt.IEncoding) (p *Local, err error) {
+	var b []byte
+	if b, err = os.ReadFile(file); err != nil {
+		return
+	}
+	if coding != nil {
+		if b, err = coding.Decode(string(bytes.TrimSpace(b))); err != nil {
+			return
+		}
+	}
+	return NewLocal(b)
+}
+
+func (p *Local) GenerateDataKey() (plaintext, wrapped []byte, err error) {
+	plaintext = make([]byte, dataKeySize)
+	if _, err = io.ReadFull(rand.Reader, plaintext); err != nil {
+		return
+	}
+
+	nonce := make([]byte, p.aead.NonceSize(), p.aead.NonceSize()+dataKeySize+p.aead.Overhead())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return
+	}
+	wrapped = p.aead.Seal(nonce, nonce, plaintext, nil)
+	return
+}
+
+func (p *Local) UnwrapDataKey(wrapped []byte) (plaintext []byte, err error) {
+	if len(wrapped) < p.aead.NonceSize()+p.aead.Overhead() {
+		err = ErrFormat
+		return
+	}
+	return p.aead.Open(nil, wrapped[:p.aead.NonceSize()], wrapped[p.aead.NonceSize():], nil)
+}
+
+func validKey(k []byte) bool {
+	switch len(k) {
+	case 16, 24, 32:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+)
+
+var (
+	ErrVerify     = errors.New("sm2: verification error")
+	ErrCiphertext = errors.New("sm2: ciphertext too short")
+)
+
+// 密文的拼接顺序,C1为随机点(04||X||Y),C2为密文,C3为SM3摘要
+const (
+	// ModeC1C3C2 GM/T 0009-2012之后的标准顺序
+	ModeC1C3C2 = 0
+	// ModeC1C2C3 旧标准顺序,部分老系统仍在使用
+	ModeC1C2C3 = 1
+)
+
+// c1 04||X||Y + c3 SM3摘要
+const overhead = 1 + 64 + 32
+
+// SM2 国密SM2,签名为SM2-SM3,签名值为ASN.1 DER格式;加密密文为C1C3C2或C1C2C3拼接格式
+type SM2 struct {
+	publicKey  []byte
+	privateKey []byte
+	uid        []byte
+	mode       int
+	coding     crypt.IEncoding
+	rand       io.Reader
+}
+
+type opt func(o *SM2)
+
+// WithPublicKey PEM格式或hex格式(04||X||Y)
+func WithPublicKey(pub string) opt {
+	return func(o *SM2) {
+		o.publicKey = []byte(pub)
+	}
+}
+
+// WithPrivateKey PEM格式(PKCS#8)或hex格式(D)
+func WithPrivateKey(priv string) opt {
+	return func(o *SM2) {
+		o.privateKey = []byte(priv)
+	}
+}
+
+// WithUid 签名的用户标识,默认1234567812345678
+func WithUid(uid string) opt {
+	return func(o *SM2) {
+		o.uid = []byte(uid)
+	}
+}
+
+// WithMode 加密密文的拼接顺序,默认ModeC1C3C2,需与对端一致
+func WithMode(mode int) opt {
+	return func(o *SM2) {
+		o.mode = mode
+	}
+}
+func WithEncoding(coding crypt.IEncoding) opt {
+	return func(o *SM2) {
+		o.coding = coding
+	}
+}
+
+// WithRand 加密与签名的随机源,默认crypto/rand,仅测试时替换
+func WithRand(r io.Reader) opt {
+	return func(o *SM2) {
+		o.rand = r
+	}
+}
+
+func New(opts ...opt) (os *SM2) {
+	os = &SM2{
+		mode:   ModeC1C3C2,
+		coding: std.New(),
+		rand:   rand.Reader,
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *SM2) Encrypt(origin []byte) (encrypt string, err error) {
+	var pub *sm2.PublicKey
+	if pub, err = o.public(); err != nil {
+		return
+	}
+
+	var en []byte
+	if en, err = sm2.Encrypt(pub, origin, o.rand, o.mode); err != nil {
+		return
+	}
+	encrypt = o.coding.Encode(en)
+	return
+}
+
+func (o *SM2) Decrypt(encrypt string) (origin []byte, err error) {
+	var priv *sm2.PrivateKey
+	if priv, err = o.private(); err != nil {
+		return
+	}
+
+	var en []byte
+	if en, err = o.coding.Decode(encrypt); err != nil {
+		return
+	}
+	if len(en) < overhead {
+		err = ErrCiphertext
+		return
+	}
+	// 摘要校验失败时库仍会返回解出的数据,需丢弃
+	if origin, err = sm2.Decrypt(priv, en, o.mode); err != nil {
+		origin = nil
+	}
+	return
+}
+
+func (o *SM2) Sign(origin []byte) (sign string, err error) {
+	var priv *sm2.PrivateKey
+	if priv, err = o.private(); err != nil {
+		return
+	}
+
+	var s []byte
+	if s, err = o.sign(priv, origin); err != nil {
+		return
+	}
+	sign = o.coding.Encode(s)
+	return
+}
+
+func (o *SM2) Verify(origin []byte, sign string) (err error) {
+	var pub *sm2.PublicKey
+	if pub, err = o.public(); err != nil {
+		return
+	}
+
+	var s []byte
+	if s, err = o.coding.Decode(sign); err != nil {
+		return
+	}
+	r, ss, err := sm2.SignDataToSignDigit(s)
+	if err != nil {
+		return
+	}
+	if !sm2.Sm2Verify(pub, origin, o.uid, r, ss) {
+		err = ErrVerify
+	}
+	return
+}
+
+func (o *SM2) sign(priv *sm2.PrivateKey, origin []byte) (s []byte, err error) {
+	r, ss, err := sm2.Sm2Sign(priv, origin, o.uid, o.rand)
+	if err != nil {
+		return
+	}
+	return sm2.SignDigitToSignData(r, ss)
+}
+
+func (o *SM2) public() (*sm2.PublicKey, error) {
+	if block, _ := pem.Decode(o.publicKey); block != nil {
+		return x509.ReadPublicKeyFromPem(o.publicKey)
+	}
+	return x509.ReadPublicKeyFromHex(string(o.publicKey))
+}
+
+func (o *SM2) private() (*sm2.PrivateKey, error) {
+	if block, _ := pem.Decode(o.privateKey); block != nil {
+		return x509.ReadPrivateKeyFromPem(o.privateKey, nil)
+	}
+	return x509.ReadPrivateKeyFromHex(string(o.privateKey))
+}
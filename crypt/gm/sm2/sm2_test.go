@@ -0,0 +1,65 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+func genKey(t *testing.T) (pub, priv string) {
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPem, _ := x509.WritePublicKeyToPem(&key.PublicKey)
+	privPem, _ := x509.WritePrivateKeyToPem(key, nil)
+	return string(pubPem), string(privPem)
+}
+
+func TestSign(t *testing.T) {
+	pub, priv := genKey(t)
+	cnt := []byte("app_id=1&biz_content=abc&timestamp=2023-09-25")
+
+	cr := New(WithPublicKey(pub), WithPrivateKey(priv))
+	sign, err := cr.Sign(cnt)
+	if err != nil {
+		t.Fatalf("Sign: %+v", err)
+	}
+	if err = cr.Verify(cnt, sign); err != nil {
+		t.Errorf("Verify: %+v", err)
+	}
+	if err = cr.Verify(append(cnt, '1'), sign); err != ErrVerify {
+		t.Errorf("Verify tampered content: %+v", err)
+	}
+	if err = New(WithPublicKey(pub), WithUid("other")).Verify(cnt, sign); err != ErrVerify {
+		t.Errorf("Verify with another uid: %+v", err)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	pub, priv := genKey(t)
+	cnt := []byte("abcdefghijklmnopqrstuvwxyz1234567890")
+
+	for _, mode := range []int{ModeC1C3C2, ModeC1C2C3} {
+		cr := New(WithPublicKey(pub), WithPrivateKey(priv), WithMode(mode))
+		en, err := cr.Encrypt(cnt)
+		if err != nil {
+			t.Fatalf("Encrypt: %+v", err)
+		}
+		origin, err := cr.Decrypt(en)
+		if err != nil || string(origin) != string(cnt) {
+			t.Errorf("origin, err:\t%+v,%+v", string(origin), err)
+		}
+
+		other := New(WithPrivateKey(priv), WithMode(1-mode))
+		if origin, err = other.Decrypt(en); err == nil || origin != nil {
+			t.Errorf("decrypt with another mode:\t%+v,%+v", string(origin), err)
+		}
+	}
+
+	if _, err := New(WithPrivateKey(priv)).Decrypt("BAE="); err != ErrCiphertext {
+		t.Errorf("short ciphertext: %+v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package sm3
+
+import (
+	"crypto/hmac"
+	"errors"
+	"hash"
+
+	"github.com/tjfoc/gmsm/sm3"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+)
+
+var ErrVerify = errors.New("sm3: verification error")
+
+// NewHash 返回SM3的hash.Hash,可用于hmac.WithHash等需要哈希构造函数的场景
+func NewHash() hash.Hash {
+	return sm3.New()
+}
+
+// SM3 国密SM3摘要,设置密钥时为HMAC-SM3,实现crypt.ISigner
+type SM3 struct {
+	key    []byte
+	coding crypt.IEncoding
+}
+
+type opt func(o *SM3)
+
+// WithKey 设置后使用HMAC-SM3,否则为普通摘要
+func WithKey(key string) opt {
+	return func(o *SM3) {
+		o.key = []byte(key)
+	}
+}
+func WithEncoding(coding crypt.IEncoding) opt {
+	return func(o *SM3) {
+		o.coding = coding
+	}
+}
+
+func New(opts ...opt) (os *SM3) {
+	os = &SM3{
+		coding: std.New(),
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *SM3) Sum(origin []byte) (sum string) {
+	return o.coding.Encode(o.sum(origin))
+}
+
+func (o *SM3) Sign(origin []byte) (sign string, err error) {
+	sign = o.Sum(origin)
+	return
+}
+
+// Verify 常量时间比较,避免时序攻击
+func (o *SM3) Verify(origin []byte, sign string) (err error) {
+	var s []byte
+	if s, err = o.coding.Decode(sign); err != nil {
+		return
+	}
+	if !hmac.Equal(s, o.sum(origin)) {
+		err = ErrVerify
+	}
+	return
+}
+
+func (o *SM3) sum(origin []byte) []byte {
+	if o.key == nil {
+		return sm3.Sm3Sum(origin)
+	}
+	h := hmac.New(sm3.New, o.key)
+	h.Write(origin)
+	return h.Sum(nil)
+}
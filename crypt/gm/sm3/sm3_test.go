@@ -0,0 +1,29 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// GB/T 32905-2016 附录A 示例1
+func TestSum(t *testing.T) {
+	cr := New()
+	s, _ := cr.coding.Decode(cr.Sum([]byte("abc")))
+	if hex.EncodeToString(s) != "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0" {
+		t.Errorf("sum:\t%x", s)
+	}
+}
+
+func TestSign(t *testing.T) {
+	cr := New(WithKey("secret"))
+	sign, _ := cr.Sign([]byte("abc"))
+	if sign == New().Sum([]byte("abc")) {
+		t.Errorf("hmac is not keyed: %s", sign)
+	}
+	if err := cr.Verify([]byte("abc"), sign); err != nil {
+		t.Errorf("Verify: %+v", err)
+	}
+	if err := New(WithKey("other")).Verify([]byte("abc"), sign); err != ErrVerify {
+		t.Errorf("Verify with another key: %+v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/forgoer/openssl"
+	"github.com/tjfoc/gmsm/sm4"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+)
+
+var (
+	ErrCiphertext = errors.New("sm4: invalid ciphertext length")
+	ErrMode       = errors.New("sm4: unsupported mode")
+	ErrIv         = errors.New("sm4: iv must be 16 bytes in CBC mode")
+)
+
+const (
+	ModeECB = "ECB"
+	ModeCBC = "CBC"
+	// ModeGCM 每次加密生成随机nonce并拼接在密文之前: nonce|ciphertext|tag
+	ModeGCM = "GCM"
+)
+
+// SM4 国密SM4分组密码,密钥16字节
+type SM4 struct {
+	mode    string
+	padding string
+	key     []byte
+	iv      []byte
+	aad     []byte
+	coding  crypt.IEncoding
+	rand    io.Reader
+}
+
+type opt func(o *SM4)
+
+// WithMode 工作模式,默认ModeCBC
+func WithMode(mode string) opt {
+	return func(o *SM4) {
+		o.mode = mode
+	}
+}
+
+// WithPadding ECB/CBC的填充方式,默认PKCS7
+func WithPadding(padding string) opt {
+	return func(o *SM4) {
+		o.padding = padding
+	}
+}
+func WithKey(key string) opt {
+	return func(o *SM4) {
+		o.key = []byte(key)
+	}
+}
+
+// WithIv CBC的初始向量,须为16字节,默认的CBC模式未设置时加解密返回ErrIv
+func WithIv(iv string) opt {
+	return func(o *SM4) {
+		o.iv = []byte(iv)
+	}
+}
+
+// WithAdditionalData GCM的附加认证数据,加解密两端需一致
+func WithAdditionalData(aad string) opt {
+	return func(o *SM4) {
+		o.aad = []byte(aad)
+	}
+}
+func WithEncoding(coding crypt.IEncoding) opt {
+	return func(o *SM4) {
+		o.coding = coding
+	}
+}
+
+// WithRand GCM nonce的随机源,默认crypto/rand,仅测试时替换
+func WithRand(r io.Reader) opt {
+	return func(o *SM4) {
+		o.rand = r
+	}
+}
+
+func New(opts ...opt) (os *SM4) {
+	os = &SM4{
+		mode:    ModeCBC,
+		padding: openssl.PKCS7_PADDING,
+		coding:  std.New(),
+		rand:    rand.Reader,
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *SM4) Encrypt(origin []byte) (encrypt string, err error) {
+	var block cipher.Block
+	if block, err = sm4.NewCipher(o.key); err != nil {
+		return
+	}
+
+	var en []byte
+	switch o.mode {
+	case ModeECB:
+		en, err = openssl.ECBEncrypt(block, origin, o.padding)
+	case ModeCBC:
+		// forgoer会把不足16字节的iv补零,等同于使用全零iv
+		if len(o.iv) != sm4.BlockSize {
+			err = ErrIv
+			return
+		}
+		en, err = openssl.CBCEncrypt(block, origin, o.iv, o.padding)
+	case ModeGCM:
+		en, err = o.seal(block, origin)
+	default:
+		err = ErrMode
+	}
+	if err != nil {
+		return
+	}
+	encrypt = o.coding.Encode(en)
+	return
+}
+
+func (o *SM4) Decrypt(encrypt string) (origin []byte, err error) {
+	var block cipher.Block
+	if block, err = sm4.NewCipher(o.key); err != nil {
+		return
+	}
+
+	var en []byte
+	if en, err = o.coding.Decode(encrypt); err != nil {
+		return
+	}
+	switch o.mode {
+	case ModeECB, ModeCBC:
+		if len(en) == 0 || len(en)%block.BlockSize() != 0 {
+			err = ErrCiphertext
+			return
+		}
+		if o.mode == ModeECB {
+			return openssl.ECBDecrypt(block, en, o.padding)
+		}
+		if len(o.iv) != sm4.BlockSize {
+			err = ErrIv
+			return
+		}
+		return openssl.CBCDecrypt(block, en, o.iv, o.padding)
+	case ModeGCM:
+		return o.open(block, en)
+	}
+	err = ErrMode
+	return
+}
+
+func (o *SM4) seal(block cipher.Block, origin []byte) (en []byte, err error) {
+	var aead cipher.AEAD
+	if aead, err = cipher.NewGCM(block); err != nil {
+		return
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(origin)+aead.Overhead())
+	if _, err = io.ReadFull(o.rand, nonce); err != nil {
+		return
+	}
+	en = aead.Seal(nonce, nonce, origin, o.aad)
+	return
+}
+
+func (o *SM4) open(block cipher.Block, en []byte) (origin []byte, err error) {
+	var aead cipher.AEAD
+	if aead, err = cipher.NewGCM(block); err != nil {
+		return
+	}
+
+	if len(en) < aead.NonceSize()+aead.Overhead() {
+		err = ErrCiphertext
+		return
+	}
+	return aead.Open(nil, en[:aead.NonceSize()], en[aead.NonceSize():], o.aad)
+}
@@ -0,0 +1,76 @@
+package sm4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	cnt  = "abcdefghijklmnopqrstuvwxyz1234567890------------"
+	keyM = "rW@vM2UlXKGh2V%!"
+	ivM  = "Kq0%4bX!z8Vd3@Lm"
+)
+
+func TestEncrypt(t *testing.T) {
+	for _, cr := range []*SM4{
+		New(WithMode(ModeECB), WithKey(keyM)),
+		New(WithMode(ModeCBC), WithKey(keyM), WithIv(ivM)),
+		New(WithMode(ModeGCM), WithKey(keyM), WithAdditionalData("uid:1")),
+	} {
+		en, err := cr.Encrypt([]byte(cnt))
+		if err != nil {
+			t.Fatalf("%s Encrypt: %+v", cr.mode, err)
+		}
+		origin, err := cr.Decrypt(en)
+		if err != nil || string(origin) != cnt {
+			t.Errorf("%s origin, err:\t%+v,%+v", cr.mode, string(origin), err)
+		}
+	}
+}
+
+// GB/T 32907-2016 附录A 示例1
+func TestKnownAnswer(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	ct, _ := hex.DecodeString("681edf34d206965e86b3e94f536e4246")
+
+	cr := New(WithMode(ModeECB), WithKey(string(key)))
+	en, err := cr.Encrypt(key)
+	if err != nil {
+		t.Fatalf("Encrypt: %+v", err)
+	}
+	raw, _ := cr.coding.Decode(en)
+	if !bytes.Equal(raw[:16], ct) {
+		t.Errorf("ciphertext:\t%x", raw)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	cr := New(WithMode(ModeGCM), WithKey(keyM))
+	en, _ := cr.Encrypt([]byte(cnt))
+	raw, _ := cr.coding.Decode(en)
+	raw[len(raw)-1] ^= 1
+	if _, err := cr.Decrypt(cr.coding.Encode(raw)); err == nil {
+		t.Error("tampered ciphertext is accepted")
+	}
+
+	cr = New(WithMode(ModeCBC), WithKey(keyM), WithIv(ivM))
+	if _, err := cr.Decrypt(cr.coding.Encode(raw[:17])); err != ErrCiphertext {
+		t.Errorf("short ciphertext: %+v", err)
+	}
+}
+
+// CBC未设置iv时报错,而不是使用全零iv
+func TestIv(t *testing.T) {
+	for _, cr := range []*SM4{
+		New(WithKey(keyM)),
+		New(WithMode(ModeCBC), WithKey(keyM), WithIv("short")),
+	} {
+		if _, err := cr.Encrypt([]byte(cnt)); err != ErrIv {
+			t.Errorf("Encrypt with iv %q: %+v", cr.iv, err)
+		}
+		if _, err := cr.Decrypt(cr.coding.Encode(make([]byte, 16))); err != ErrIv {
+			t.Errorf("Decrypt with iv %q: %+v", cr.iv, err)
+		}
+	}
+}
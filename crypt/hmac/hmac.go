@@ -0,0 +1,74 @@
+package hmac
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"hash"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+)
+
+var ErrVerify = errors.New("hmac: verification error")
+
+// HMAC 签名,默认HMAC-SHA256
+type HMAC struct {
+	key    []byte
+	hash   func() hash.Hash
+	coding crypt.IEncoding
+}
+
+type opt func(o *HMAC)
+
+func WithKey(key string) opt {
+	return func(o *HMAC) {
+		o.key = []byte(key)
+	}
+}
+
+// WithHash 哈希算法,默认sha256.New
+func WithHash(fn func() hash.Hash) opt {
+	return func(o *HMAC) {
+		o.hash = fn
+	}
+}
+func WithEncoding(coding crypt.IEncoding) opt {
+	return func(o *HMAC) {
+		o.coding = coding
+	}
+}
+
+func New(opts ...opt) (os *HMAC) {
+	os = &HMAC{
+		hash:   sha256.New,
+		coding: std.New(),
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *HMAC) Sign(origin []byte) (sign string, err error) {
+	sign = o.coding.Encode(o.sum(origin))
+	return
+}
+
+// Verify 常量时间比较,避免时序攻击
+func (o *HMAC) Verify(origin []byte, sign string) (err error) {
+	var s []byte
+	if s, err = o.coding.Decode(sign); err != nil {
+		return
+	}
+	if !hmac.Equal(s, o.sum(origin)) {
+		err = ErrVerify
+	}
+	return
+}
+
+func (o *HMAC) sum(origin []byte) []byte {
+	h := hmac.New(o.hash, o.key)
+	h.Write(origin)
+	return h.Sum(nil)
+}
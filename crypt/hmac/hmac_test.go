@@ -0,0 +1,26 @@
+package hmac
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// RFC 4231, Test Case 2
+func TestSign(t *testing.T) {
+	cr := New(WithKey("Jefe"))
+	sign, err := cr.Sign([]byte("what do ya want for nothing?"))
+	if err != nil {
+		t.Fatalf("Sign: %+v", err)
+	}
+	s, _ := cr.coding.Decode(sign)
+	if hex.EncodeToString(s) != "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843" {
+		t.Errorf("sign:\t%x", s)
+	}
+
+	if err = cr.Verify([]byte("what do ya want for nothing?"), sign); err != nil {
+		t.Errorf("Verify: %+v", err)
+	}
+	if err = cr.Verify([]byte("what do ya want for nothing!"), sign); err != ErrVerify {
+		t.Errorf("Verify tampered content: %+v", err)
+	}
+}
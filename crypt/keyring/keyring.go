@@ -0,0 +1,125 @@
+package keyring
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/neo532/kratos_kit/crypt"
+)
+
+// 密文格式: keyID$密文,key id中不能含有分隔符
+const sep = "$"
+
+var (
+	ErrNoActiveKey = errors.New("keyring: no active key")
+	ErrUnknownKey  = errors.New("keyring: unknown key id")
+	ErrFormat      = errors.New("keyring: ciphertext without key id")
+)
+
+// Keyring 支持密钥轮换的crypt.ICrypt:用当前密钥加密并在密文前标记key id,
+// 解密时按key id选用任一已注册的密钥,旧密钥可一直保留到数据迁移完毕.
+type Keyring struct {
+	active string
+	keys   map[string]crypt.ICrypt
+	legacy crypt.ICrypt
+}
+
+type opt func(o *Keyring)
+
+// WithKey 注册一个密钥,可以是任意crypt.ICrypt的实现
+func WithKey(id string, c crypt.ICrypt) opt {
+	return func(o *Keyring) {
+		o.keys[id] = c
+	}
+}
+
+// WithActive 指定加密使用的key id
+func WithActive(id string) opt {
+	return func(o *Keyring) {
+		o.active = id
+	}
+}
+
+// WithLegacy 用于解密引入keyring之前、没有key id前缀的密文
+func WithLegacy(c crypt.ICrypt) opt {
+	return func(o *Keyring) {
+		o.legacy = c
+	}
+}
+
+func New(opts ...opt) (os *Keyring) {
+	os = &Keyring{
+		keys: make(map[string]crypt.ICrypt),
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *Keyring) Encrypt(origin []byte) (encrypt string, err error) {
+	c, ok := o.keys[o.active]
+	if !ok {
+		err = ErrNoActiveKey
+		return
+	}
+	if encrypt, err = c.Encrypt(origin); err != nil {
+		return
+	}
+	encrypt = o.active + sep + encrypt
+	return
+}
+
+func (o *Keyring) Decrypt(encrypt string) (origin []byte, err error) {
+	var c crypt.ICrypt
+	if c, encrypt, err = o.crypt(encrypt); err != nil {
+		return
+	}
+	return c.Decrypt(encrypt)
+}
+
+// KeyID 返回密文所用的key id,没有前缀的旧密文返回空
+func (o *Keyring) KeyID(encrypt string) (id string) {
+	if i := strings.Index(encrypt, sep); i >= 0 {
+		id = encrypt[:i]
+	}
+	return
+}
+
+// Reencrypt 用当前密钥重新加密,已是当前密钥加密的原样返回且changed为false.
+// 用于把存量数据迁移到新密钥.
+func (o *Keyring) Reencrypt(encrypt string) (re string, changed bool, err error) {
+	if i := strings.Index(encrypt, sep); i >= 0 && encrypt[:i] == o.active {
+		re = encrypt
+		return
+	}
+
+	var origin []byte
+	if origin, err = o.Decrypt(encrypt); err != nil {
+		return
+	}
+	if re, err = o.Encrypt(origin); err != nil {
+		return
+	}
+	changed = true
+	return
+}
+
+func (o *Keyring) crypt(encrypt string) (c crypt.ICrypt, inner string, err error) {
+	i := strings.Index(encrypt, sep)
+	if i < 0 {
+		if o.legacy == nil {
+			err = ErrFormat
+			return
+		}
+		return o.legacy, encrypt, nil
+	}
+
+	var ok bool
+	if c, ok = o.keys[encrypt[:i]]; !ok {
+		err = ErrUnknownKey
+		return
+	}
+	inner = encrypt[i+len(sep):]
+	return
+}
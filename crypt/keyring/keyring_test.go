@@ -0,0 +1,45 @@
+package keyring
+
+import (
+	"testing"
+
+	"github.com/neo532/kratos_kit/crypt/openssl/cbc"
+	"github.com/neo532/kratos_kit/crypt/openssl/gcm"
+)
+
+var (
+	cnt    = "abcdefghijklmnopqrstuvwxyz1234567890------------"
+	oldKey = cbc.New(cbc.WithKey("rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"), cbc.WithIv("0123456789abcdef"))
+	v1     = gcm.New(gcm.WithKey("0123456789abcdef0123456789abcdef"))
+	v2     = gcm.New(gcm.WithKey("fedcba9876543210fedcba9876543210"))
+)
+
+func TestRotate(t *testing.T) {
+	legacy, _ := oldKey.Encrypt([]byte(cnt))
+	en1, _ := New(WithKey("v1", v1), WithActive("v1")).Encrypt([]byte(cnt))
+
+	kr := New(
+		WithKey("v1", v1),
+		WithKey("v2", v2),
+		WithActive("v2"),
+		WithLegacy(oldKey),
+	)
+	for _, en := range []string{legacy, en1} {
+		re, changed, err := kr.Reencrypt(en)
+		if err != nil || !changed || kr.KeyID(re) != "v2" {
+			t.Fatalf("Reencrypt:\t%s,%+v,%+v", re, changed, err)
+		}
+		if _, changed, _ = kr.Reencrypt(re); changed {
+			t.Errorf("Reencrypt active ciphertext again")
+		}
+
+		origin, err := kr.Decrypt(re)
+		if err != nil || string(origin) != cnt {
+			t.Errorf("origin, err:\t%+v,%+v", string(origin), err)
+		}
+	}
+
+	if _, err := New(WithKey("v2", v2)).Decrypt(en1); err != ErrUnknownKey {
+		t.Errorf("Decrypt with unknown key id: %+v", err)
+	}
+}
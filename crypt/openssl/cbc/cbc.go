@@ -1,16 +1,34 @@
 package cbc
 
 import (
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
 	"github.com/forgoer/openssl"
 	"github.com/neo532/kratos_kit/crypt"
 	"github.com/neo532/kratos_kit/crypt/encoding/std"
 )
 
+var (
+	ErrCiphertext = errors.New("cbc: invalid ciphertext length")
+	ErrMac        = errors.New("cbc: message authentication failed")
+)
+
+// CBC AES-CBC,默认使用WithIv的固定iv;
+// 开启WithRandomIv后每次加密生成随机iv并拼接在密文之前: iv|ciphertext;
+// 开启WithMac后在末尾追加HMAC-SHA256: [iv|]ciphertext|mac
 type CBC struct {
-	padding string
-	key     []byte
-	iv      []byte
-	coding  crypt.IEncoding
+	padding  string
+	key      []byte
+	iv       []byte
+	randomIv bool
+	macKey   []byte
+	coding   crypt.IEncoding
+	rand     io.Reader
 }
 
 type opt func(o *CBC)
@@ -25,21 +43,46 @@ func WithKey(key string) opt {
 		o.key = []byte(key)
 	}
 }
+
+// WithIv 固定iv,相同前缀的明文会得到相同前缀的密文,仅用于对接老系统
 func WithIv(iv string) opt {
 	return func(o *CBC) {
 		o.iv = []byte(iv)
 	}
 }
+
+// WithRandomIv 每次加密生成随机iv并随密文传递,设置后忽略WithIv
+func WithRandomIv() opt {
+	return func(o *CBC) {
+		o.randomIv = true
+	}
+}
+
+// WithMac Encrypt-then-MAC,对[iv|]ciphertext计算HMAC-SHA256,
+// 解密前先校验,防止篡改和padding oracle.key需与加密密钥不同
+func WithMac(key string) opt {
+	return func(o *CBC) {
+		o.macKey = []byte(key)
+	}
+}
 func WithEncoding(coding crypt.IEncoding) opt {
 	return func(o *CBC) {
 		o.coding = coding
 	}
 }
 
+// WithRand iv的随机源,默认crypto/rand,仅测试时替换
+func WithRand(r io.Reader) opt {
+	return func(o *CBC) {
+		o.rand = r
+	}
+}
+
 func New(opts ...opt) (os *CBC) {
 	os = &CBC{
 		padding: openssl.PKCS7_PADDING,
 		coding:  std.New(),
+		rand:    rand.Reader,
 	}
 	for _, fn := range opts {
 		fn(os)
@@ -51,8 +94,25 @@ func (o *CBC) Encrypt(origin []byte) (encrypt string, err error) {
 	if len(origin) == 0 {
 		return
 	}
+
+	iv := o.iv
+	if o.randomIv {
+		iv = make([]byte, aes.BlockSize)
+		if _, err = io.ReadFull(o.rand, iv); err != nil {
+			return
+		}
+	}
+
 	var en []byte
-	en, err = openssl.AesCBCEncrypt(origin, o.key, o.iv, o.padding)
+	if en, err = openssl.AesCBCEncrypt(origin, o.key, iv, o.padding); err != nil {
+		return
+	}
+	if o.randomIv {
+		en = append(iv, en...)
+	}
+	if o.macKey != nil {
+		en = append(en, o.mac(en)...)
+	}
 	encrypt = o.coding.Encode(en)
 	return
 }
@@ -62,6 +122,40 @@ func (o *CBC) Decrypt(encrypt string) (origin []byte, err error) {
 		return
 	}
 	var en []byte
-	en, err = o.coding.Decode(encrypt)
-	return openssl.AesCBCDecrypt(en, o.key, o.iv, o.padding)
+	if en, err = o.coding.Decode(encrypt); err != nil {
+		return
+	}
+
+	if o.macKey != nil {
+		if len(en) < sha256.Size {
+			err = ErrCiphertext
+			return
+		}
+		tag := en[len(en)-sha256.Size:]
+		en = en[:len(en)-sha256.Size]
+		if !hmac.Equal(tag, o.mac(en)) {
+			err = ErrMac
+			return
+		}
+	}
+
+	iv := o.iv
+	if o.randomIv {
+		if len(en) < aes.BlockSize {
+			err = ErrCiphertext
+			return
+		}
+		iv, en = en[:aes.BlockSize], en[aes.BlockSize:]
+	}
+	if len(en) == 0 || len(en)%aes.BlockSize != 0 {
+		err = ErrCiphertext
+		return
+	}
+	return openssl.AesCBCDecrypt(en, o.key, iv, o.padding)
+}
+
+func (o *CBC) mac(en []byte) []byte {
+	h := hmac.New(sha256.New, o.macKey)
+	h.Write(en)
+	return h.Sum(nil)
 }
@@ -0,0 +1,68 @@
+package cbc
+
+import (
+	"testing"
+
+	"github.com/forgoer/openssl"
+)
+
+var (
+	cnt  = "abcdefghijklmnopqrstuvwxyz1234567890------------"
+	keyM = "rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"
+	ivM  = "Kq0%4bX!z8Vd3@Lm"
+)
+
+func TestFixedIv(t *testing.T) {
+	cr := New(WithKey(keyM), WithIv(ivM))
+	en, err := cr.Encrypt([]byte(cnt))
+	if err != nil {
+		t.Fatalf("Encrypt: %+v", err)
+	}
+	legacy, _ := openssl.AesCBCEncrypt([]byte(cnt), []byte(keyM), []byte(ivM), openssl.PKCS7_PADDING)
+	if en != cr.coding.Encode(legacy) {
+		t.Errorf("fixed iv is not compatible: %s", en)
+	}
+
+	origin, err := cr.Decrypt(en)
+	if err != nil || string(origin) != cnt {
+		t.Errorf("origin, err:\t%+v,%+v", string(origin), err)
+	}
+}
+
+func TestRandomIv(t *testing.T) {
+	for _, cr := range []*CBC{
+		New(WithKey(keyM), WithRandomIv()),
+		New(WithKey(keyM), WithRandomIv(), WithMac("mac-key")),
+		New(WithKey(keyM), WithIv(ivM), WithMac("mac-key")),
+	} {
+		en, err := cr.Encrypt([]byte(cnt))
+		if err != nil {
+			t.Fatalf("Encrypt: %+v", err)
+		}
+		if again, _ := cr.Encrypt([]byte(cnt)); cr.randomIv && again == en {
+			t.Errorf("iv is reused: %s", en)
+		}
+
+		origin, err := cr.Decrypt(en)
+		if err != nil || string(origin) != cnt {
+			t.Errorf("origin, err:\t%+v,%+v", string(origin), err)
+		}
+	}
+}
+
+func TestMac(t *testing.T) {
+	cr := New(WithKey(keyM), WithRandomIv(), WithMac("mac-key"))
+	en, _ := cr.Encrypt([]byte(cnt))
+	raw, _ := cr.coding.Decode(en)
+
+	raw[0] ^= 1
+	if _, err := cr.Decrypt(cr.coding.Encode(raw)); err != ErrMac {
+		t.Errorf("tampered iv: %+v", err)
+	}
+	if _, err := New(WithKey(keyM), WithRandomIv(), WithMac("other")).Decrypt(en); err != ErrMac {
+		t.Errorf("another mac key: %+v", err)
+	}
+	if _, err := cr.Decrypt(cr.coding.Encode(raw[:16])); err != ErrCiphertext {
+		t.Errorf("short ciphertext: %+v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package ecb
+
+import (
+	"crypto/aes"
+
+	"github.com/forgoer/openssl"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding"
+)
+
+const Algorithm = "ecb"
+
+func init() {
+	crypt.Register(Algorithm, Build)
+}
+
+// Build 由crypt.New调用,密钥须为16/24/32字节
+func Build(conf *crypt.Config) (cr crypt.ICrypt, err error) {
+	var key string
+	if key, err = conf.Key.Load(); err != nil {
+		return
+	}
+	if _, err = aes.NewCipher([]byte(key)); err != nil {
+		return
+	}
+
+	opts := []opt{WithKey(key)}
+	var padding string
+	if padding, err = conf.PaddingOr(openssl.PKCS7_PADDING, openssl.PKCS5_PADDING, openssl.PKCS7_PADDING, openssl.ZEROS_PADDING); err != nil {
+		return
+	}
+	opts = append(opts, WithPadding(padding))
+	var coding crypt.IEncoding
+	if coding, err = encoding.FromConfig(conf); err != nil {
+		return
+	}
+	opts = append(opts, WithEncoding(coding))
+	cr = New(opts...)
+	return
+}
@@ -0,0 +1,34 @@
+package gcm
+
+import (
+	"crypto/aes"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding"
+)
+
+const Algorithm = "gcm"
+
+func init() {
+	crypt.Register(Algorithm, Build)
+}
+
+// Build 由crypt.New调用,密钥须为16/24/32字节
+func Build(conf *crypt.Config) (cr crypt.ICrypt, err error) {
+	var key string
+	if key, err = conf.Key.Load(); err != nil {
+		return
+	}
+	if _, err = aes.NewCipher([]byte(key)); err != nil {
+		return
+	}
+
+	opts := []opt{WithKey(key), WithAdditionalData(conf.AdditionalData)}
+	var coding crypt.IEncoding
+	if coding, err = encoding.FromConfig(conf); err != nil {
+		return
+	}
+	opts = append(opts, WithEncoding(coding))
+	cr = New(opts...)
+	return
+}
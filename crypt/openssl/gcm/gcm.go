@@ -0,0 +1,109 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+)
+
+var ErrCiphertext = errors.New("gcm: ciphertext too short")
+
+// GCM AES-GCM认证加密,每次加密生成随机nonce并拼接在密文之前: nonce|ciphertext|tag
+type GCM struct {
+	key       []byte
+	aad       []byte
+	chunkSize int
+	coding    crypt.IEncoding
+	rand      io.Reader
+}
+
+type opt func(o *GCM)
+
+// WithKey 长度为16/24/32,分别对应AES-128/192/256
+func WithKey(key string) opt {
+	return func(o *GCM) {
+		o.key = []byte(key)
+	}
+}
+
+// WithAdditionalData 附加认证数据,参与校验但不加密,加解密两端需一致
+func WithAdditionalData(aad string) opt {
+	return func(o *GCM) {
+		o.aad = []byte(aad)
+	}
+}
+
+// WithChunkSize 流式加密时每段明文的长度,默认64KB,解密端从密文头读取
+func WithChunkSize(size int) opt {
+	return func(o *GCM) {
+		o.chunkSize = size
+	}
+}
+func WithEncoding(coding crypt.IEncoding) opt {
+	return func(o *GCM) {
+		o.coding = coding
+	}
+}
+
+// WithRand nonce的随机源,默认crypto/rand,仅测试时替换
+func WithRand(r io.Reader) opt {
+	return func(o *GCM) {
+		o.rand = r
+	}
+}
+
+func New(opts ...opt) (os *GCM) {
+	os = &GCM{
+		chunkSize: defaultChunkSize,
+		coding:    std.New(),
+		rand:      rand.Reader,
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *GCM) Encrypt(origin []byte) (encrypt string, err error) {
+	var aead cipher.AEAD
+	if aead, err = o.aead(); err != nil {
+		return
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(origin)+aead.Overhead())
+	if _, err = io.ReadFull(o.rand, nonce); err != nil {
+		return
+	}
+	encrypt = o.coding.Encode(aead.Seal(nonce, nonce, origin, o.aad))
+	return
+}
+
+func (o *GCM) Decrypt(encrypt string) (origin []byte, err error) {
+	var aead cipher.AEAD
+	if aead, err = o.aead(); err != nil {
+		return
+	}
+
+	var en []byte
+	if en, err = o.coding.Decode(encrypt); err != nil {
+		return
+	}
+	if len(en) < aead.NonceSize()+aead.Overhead() {
+		err = ErrCiphertext
+		return
+	}
+	return aead.Open(nil, en[:aead.NonceSize()], en[aead.NonceSize():], o.aad)
+}
+
+func (o *GCM) aead() (aead cipher.AEAD, err error) {
+	var block cipher.Block
+	if block, err = aes.NewCipher(o.key); err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
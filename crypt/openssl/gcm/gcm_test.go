@@ -0,0 +1,66 @@
+package gcm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	cnt  = "abcdefghijklmnopqrstuvwxyz1234567890------------"
+	keyM = "rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"
+)
+
+func TestEncrypt(t *testing.T) {
+	cr := New(
+		WithKey(keyM),
+		WithAdditionalData("uid:1"),
+	)
+	en, err := cr.Encrypt([]byte(cnt))
+	if err != nil {
+		t.Fatalf("Encrypt: %+v", err)
+	}
+	if again, _ := cr.Encrypt([]byte(cnt)); again == en {
+		t.Errorf("nonce is reused: %s", en)
+	}
+
+	origin, err := cr.Decrypt(en)
+	if err != nil || string(origin) != cnt {
+		t.Errorf("origin, err:\t%+v,%+v", string(origin), err)
+	}
+}
+
+// NIST GCM test vector: gcmEncryptExtIV128.rsp, Count = 0 (Keylen 128, PTlen 128, AADlen 0)
+func TestKnownAnswer(t *testing.T) {
+	key, _ := hex.DecodeString("7fddb57453c241d03efbed3ac44e371c")
+	nonce, _ := hex.DecodeString("ee283a3fc75575e33efd4887")
+	pt, _ := hex.DecodeString("d5de42b461646c255c87bd2962d3b9a2")
+	ct, _ := hex.DecodeString("2ccda4a5415cb91e135c2a0f78c9b2fd" + "b36d1df9b9d5e596f83e8b7f52971cb3")
+
+	cr := New(
+		WithKey(string(key)),
+		WithRand(bytes.NewReader(nonce)),
+	)
+	en, err := cr.Encrypt(pt)
+	if err != nil {
+		t.Fatalf("Encrypt: %+v", err)
+	}
+	raw, _ := cr.coding.Decode(en)
+	if !bytes.Equal(raw, append(nonce, ct...)) {
+		t.Errorf("ciphertext:\t%x", raw)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	cr := New(WithKey(keyM), WithAdditionalData("uid:1"))
+	en, _ := cr.Encrypt([]byte(cnt))
+
+	raw, _ := cr.coding.Decode(en)
+	raw[len(raw)-1] ^= 1
+	if _, err := cr.Decrypt(cr.coding.Encode(raw)); err == nil {
+		t.Errorf("tampered ciphertext is accepted")
+	}
+	if _, err := New(WithKey(keyM), WithAdditionalData("uid:2")).Decrypt(en); err == nil {
+		t.Errorf("wrong additional data is accepted")
+	}
+}
@@ -0,0 +1,169 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// 流式密文格式:
+//
+//	header: version(1) | chunkSize(4) | salt(32) | noncePrefix(7)
+//	chunk:  ciphertext|tag,除最后一段外明文均为chunkSize
+//
+// 每个流用 HKDF-SHA256(key, salt) 派生独立的子密钥,段计数从0开始也不会与其他流的nonce重复;
+// 只靠7字节的随机前缀,同一密钥下约2^28个流后nonce就可能碰撞.
+// 每段的nonce为 noncePrefix | counter(4) | last(1),
+// 段序号与结束标记参与认证,调换、删除、截断都会导致解密失败.
+// header作为每段的附加认证数据.
+const (
+	streamVersion    = 2
+	saltSize         = 32
+	noncePrefixSize  = 7
+	headerSize       = 1 + 4 + saltSize + noncePrefixSize
+	defaultChunkSize = 64 * 1024
+	maxChunkSize     = 16 * 1024 * 1024
+)
+
+const streamInfo = "kratos_kit gcm stream"
+
+var (
+	ErrStreamHeader  = errors.New("gcm: invalid stream header")
+	ErrStreamTooLong = errors.New("gcm: stream exceeds the maximum number of chunks")
+	ErrStream        = errors.New("gcm: stream is corrupted or truncated")
+)
+
+// EncryptStream 分段加密,内存占用约为两个chunk
+func (o *GCM) EncryptStream(dst io.Writer, src io.Reader) (err error) {
+	if o.chunkSize <= 0 || o.chunkSize > maxChunkSize {
+		return ErrStreamHeader
+	}
+	header := make([]byte, headerSize)
+	header[0] = streamVersion
+	binary.BigEndian.PutUint32(header[1:5], uint32(o.chunkSize))
+	if _, err = io.ReadFull(o.rand, header[5:]); err != nil {
+		return
+	}
+	var aead cipher.AEAD
+	if aead, err = o.streamAEAD(header); err != nil {
+		return
+	}
+	if _, err = dst.Write(header); err != nil {
+		return
+	}
+
+	s := newStream(aead, header, o.aad)
+	out := make([]byte, 0, o.chunkSize+aead.Overhead())
+	return readChunks(src, o.chunkSize, func(chunk []byte, last bool) (err error) {
+		var nonce []byte
+		if nonce, err = s.next(last); err != nil {
+			return
+		}
+		_, err = dst.Write(aead.Seal(out[:0], nonce, chunk, s.aad))
+		return
+	})
+}
+
+// DecryptStream 分段解密,认证失败时已写入dst的数据需由调用方丢弃
+func (o *GCM) DecryptStream(dst io.Writer, src io.Reader) (err error) {
+	header := make([]byte, headerSize)
+	if _, err = io.ReadFull(src, header); err != nil {
+		return ErrStreamHeader
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[1:5]))
+	if header[0] != streamVersion || chunkSize <= 0 || chunkSize > maxChunkSize {
+		return ErrStreamHeader
+	}
+	var aead cipher.AEAD
+	if aead, err = o.streamAEAD(header); err != nil {
+		return
+	}
+
+	s := newStream(aead, header, o.aad)
+	out := make([]byte, 0, chunkSize)
+	return readChunks(src, chunkSize+aead.Overhead(), func(chunk []byte, last bool) (err error) {
+		var nonce []byte
+		if nonce, err = s.next(last); err != nil {
+			return
+		}
+		var origin []byte
+		if origin, err = aead.Open(out[:0], nonce, chunk, s.aad); err != nil {
+			return ErrStream
+		}
+		_, err = dst.Write(origin)
+		return
+	})
+}
+
+// streamAEAD 用header中的salt派生与主密钥等长的子密钥
+func (o *GCM) streamAEAD(header []byte) (aead cipher.AEAD, err error) {
+	if _, err = aes.NewCipher(o.key); err != nil {
+		return
+	}
+	key := make([]byte, len(o.key))
+	if _, err = io.ReadFull(hkdf.New(sha256.New, o.key, header[5:5+saltSize], []byte(streamInfo)), key); err != nil {
+		return
+	}
+	var block cipher.Block
+	if block, err = aes.NewCipher(key); err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
+
+type stream struct {
+	nonce   []byte
+	aad     []byte
+	counter uint64
+}
+
+func newStream(aead cipher.AEAD, header, aad []byte) *stream {
+	s := &stream{
+		nonce: make([]byte, aead.NonceSize()),
+		aad:   append(append(make([]byte, 0, len(header)+len(aad)), header...), aad...),
+	}
+	copy(s.nonce, header[5+saltSize:])
+	return s
+}
+
+func (s *stream) next(last bool) (nonce []byte, err error) {
+	if s.counter > math.MaxUint32 {
+		err = ErrStreamTooLong
+		return
+	}
+	binary.BigEndian.PutUint32(s.nonce[noncePrefixSize:], uint32(s.counter))
+	s.nonce[len(s.nonce)-1] = 0
+	if last {
+		s.nonce[len(s.nonce)-1] = 1
+	}
+	s.counter++
+	return s.nonce, nil
+}
+
+// readChunks 按size读取src,多读1字节用于判断是否为最后一段,空输入也会产生一个最后段
+func readChunks(src io.Reader, size int, fn func(chunk []byte, last bool) error) (err error) {
+	buf := make([]byte, size+1)
+	n, err := io.ReadFull(src, buf)
+	for {
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return
+		}
+		if n > size {
+			n = size
+		}
+		if err = fn(buf[:n], last); err != nil || last {
+			return
+		}
+
+		buf[0] = buf[size]
+		n, err = io.ReadFull(src, buf[1:])
+		n++
+	}
+}
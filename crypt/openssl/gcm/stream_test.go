@@ -0,0 +1,78 @@
+package gcm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	cr := New(
+		WithKey(keyM),
+		WithAdditionalData("export:1"),
+		WithChunkSize(64),
+	)
+	for _, size := range []int{0, 1, 63, 64, 65, 64 * 3, 1000} {
+		origin := make([]byte, size)
+		rand.Read(origin)
+
+		var en, de bytes.Buffer
+		if err := cr.EncryptStream(&en, bytes.NewReader(origin)); err != nil {
+			t.Fatalf("%d EncryptStream: %+v", size, err)
+		}
+		if err := cr.DecryptStream(&de, bytes.NewReader(en.Bytes())); err != nil {
+			t.Fatalf("%d DecryptStream: %+v", size, err)
+		}
+		if !bytes.Equal(de.Bytes(), origin) {
+			t.Errorf("%d origin:\t%x", size, de.Bytes())
+		}
+	}
+}
+
+func TestStreamTamper(t *testing.T) {
+	cr := New(WithKey(keyM), WithChunkSize(64))
+	origin := make([]byte, 64*3)
+	var en bytes.Buffer
+	cr.EncryptStream(&en, bytes.NewReader(origin))
+	raw := en.Bytes()
+	chunk := 64 + 16
+
+	for name, c := range map[string][]byte{
+		"flip":     append(append([]byte{}, raw[:len(raw)-1]...), raw[len(raw)-1]^1),
+		"truncate": raw[:headerSize+chunk*2],
+		"reorder":  append(append(append([]byte{}, raw[:headerSize]...), raw[headerSize+chunk:headerSize+chunk*2]...), raw[headerSize:headerSize+chunk]...),
+		"aad":      raw,
+	} {
+		d := cr
+		if name == "aad" {
+			d = New(WithKey(keyM), WithAdditionalData("other"))
+		}
+		if err := d.DecryptStream(&bytes.Buffer{}, bytes.NewReader(c)); err != ErrStream {
+			t.Errorf("%s: %+v", name, err)
+		}
+	}
+
+	if err := cr.DecryptStream(&bytes.Buffer{}, bytes.NewReader(raw[:5])); err != ErrStreamHeader {
+		t.Errorf("short header: %+v", err)
+	}
+}
+
+// 每个流使用不同的salt派生子密钥
+func TestStreamSubkey(t *testing.T) {
+	cr := New(WithKey(keyM), WithChunkSize(64))
+	origin := make([]byte, 100)
+
+	var a, b bytes.Buffer
+	cr.EncryptStream(&a, bytes.NewReader(origin))
+	cr.EncryptStream(&b, bytes.NewReader(origin))
+	if bytes.Equal(a.Bytes()[5:headerSize], b.Bytes()[5:headerSize]) {
+		t.Fatal("salt is reused")
+	}
+
+	// 只替换salt,子密钥不同导致解密失败
+	raw := append([]byte{}, a.Bytes()...)
+	copy(raw[5:5+saltSize], b.Bytes()[5:5+saltSize])
+	if err := cr.DecryptStream(&bytes.Buffer{}, bytes.NewReader(raw)); err != ErrStream {
+		t.Errorf("replaced salt: %+v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package rsa
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding"
+)
+
+const Algorithm = "rsa"
+
+func init() {
+	crypt.Register(Algorithm, Build)
+}
+
+// Build 由crypt.New调用,公钥私钥至少配置一个,配置了的会预先解析
+func Build(conf *crypt.Config) (cr crypt.ICrypt, err error) {
+	if conf.PublicKey.IsZero() && conf.PrivateKey.IsZero() {
+		err = errors.New("public_key or private_key is required")
+		return
+	}
+
+	var padding string
+	if padding, err = conf.PaddingOr(PaddingPKCS1v15, PaddingPKCS1v15, PaddingOAEP); err != nil {
+		return
+	}
+	opts := []opt{WithPadding(padding)}
+	if !conf.PublicKey.IsZero() {
+		var pub string
+		if pub, err = conf.PublicKey.Load(); err != nil {
+			return
+		}
+		opts = append(opts, WithPublicKey(pub))
+	}
+	if !conf.PrivateKey.IsZero() {
+		var priv string
+		if priv, err = conf.PrivateKey.Load(); err != nil {
+			return
+		}
+		opts = append(opts, WithPrivateKey(priv))
+	}
+	var coding crypt.IEncoding
+	if coding, err = encoding.FromConfig(conf); err != nil {
+		return
+	}
+	opts = append(opts, WithEncoding(coding))
+
+	os := New(opts...)
+	if os.publicKey != nil {
+		var pub *rsa.PublicKey
+		if pub, err = os.public(); err != nil {
+			err = fmt.Errorf("public_key: %w", err)
+			return
+		}
+		if _, err = os.blockSize(pub); err != nil {
+			err = fmt.Errorf("public_key: %w", err)
+			return
+		}
+	}
+	if os.privateKey != nil {
+		if _, err = os.private(); err != nil {
+			err = fmt.Errorf("private_key: %w", err)
+			return
+		}
+	}
+	cr = os
+	return
+}
@@ -1,30 +1,93 @@
 package rsa
 
 import (
-	"github.com/forgoer/openssl"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 
 	"github.com/neo532/kratos_kit/crypt"
 	"github.com/neo532/kratos_kit/crypt/encoding/std"
 )
 
+// 加密的填充方式
+const (
+	PaddingPKCS1v15 = "PKCS1v15"
+	PaddingOAEP     = "OAEP"
+)
+
+// 签名方式
+const (
+	SignPKCS1v15 = "PKCS1v15"
+	SignPSS      = "PSS"
+)
+
+var (
+	ErrKeyFormat  = errors.New("rsa: key is invalid format")
+	ErrKeyType    = errors.New("rsa: the kind of key is not a rsa key")
+	ErrCiphertext = errors.New("rsa: ciphertext length is not a multiple of the key size")
+	ErrKeySize    = errors.New("rsa: key is too short for the padding and OAEP hash")
+	ErrHash       = errors.New("rsa: hash function is unknown or not linked into the binary")
+)
+
 type RSA struct {
 	publicKey  []byte
 	privateKey []byte
+	padding    string
+	signType   string
+	oaepHash   crypto.Hash
+	signHash   crypto.Hash
 	coding     crypt.IEncoding
 }
 
 type opt func(o *RSA)
 
+// WithPublicKey PEM格式,支持PKIX和PKCS#1
 func WithPublicKey(pub string) opt {
 	return func(o *RSA) {
 		o.publicKey = []byte(pub)
 	}
 }
+
+// WithPrivateKey PEM格式,支持PKCS#1和PKCS#8
 func WithPrivateKey(priv string) opt {
 	return func(o *RSA) {
 		o.privateKey = []byte(priv)
 	}
 }
+
+// WithPadding 加密的填充方式,默认PKCS1v15
+func WithPadding(padding string) opt {
+	return func(o *RSA) {
+		o.padding = padding
+	}
+}
+
+// WithSignType 签名方式,默认PKCS1v15
+func WithSignType(signType string) opt {
+	return func(o *RSA) {
+		o.signType = signType
+	}
+}
+
+// WithOAEPHash OAEP填充使用的哈希,默认SHA256,需与对方一致(Java默认SHA1)
+func WithOAEPHash(hash crypto.Hash) opt {
+	return func(o *RSA) {
+		o.oaepHash = hash
+	}
+}
+
+// WithSignHash 签名使用的哈希,默认SHA256,部分老接口需要SHA1
+func WithSignHash(hash crypto.Hash) opt {
+	return func(o *RSA) {
+		o.signHash = hash
+	}
+}
 func WithEncoding(coding crypt.IEncoding) opt {
 	return func(o *RSA) {
 		o.coding = coding
@@ -33,7 +96,11 @@ func WithEncoding(coding crypt.IEncoding) opt {
 
 func New(opts ...opt) (os *RSA) {
 	os = &RSA{
-		coding: std.New(),
+		padding:  PaddingPKCS1v15,
+		signType: SignPKCS1v15,
+		oaepHash: crypto.SHA256,
+		signHash: crypto.SHA256,
+		coding:   std.New(),
 	}
 	for _, fn := range opts {
 		fn(os)
@@ -41,15 +108,187 @@ func New(opts ...opt) (os *RSA) {
 	return os
 }
 
+// Encrypt 超过密钥单次可加密长度的明文会分段加密,密文依次拼接
 func (o *RSA) Encrypt(origin []byte) (encrypt string, err error) {
-	var en []byte
-	en, err = openssl.RSAEncrypt(origin, o.publicKey)
+	var pub *rsa.PublicKey
+	if pub, err = o.public(); err != nil {
+		return
+	}
+
+	var size int
+	if size, err = o.blockSize(pub); err != nil {
+		return
+	}
+
+	en := make([]byte, 0, (len(origin)/size+1)*pub.Size())
+	for first := true; first || len(origin) > 0; first = false {
+		n := size
+		if len(origin) < n {
+			n = len(origin)
+		}
+
+		var block []byte
+		if block, err = o.encrypt(pub, origin[:n]); err != nil {
+			return
+		}
+		en = append(en, block...)
+		origin = origin[n:]
+	}
 	encrypt = o.coding.Encode(en)
 	return
 }
 
 func (o *RSA) Decrypt(encrypt string) (origin []byte, err error) {
+	var priv *rsa.PrivateKey
+	if priv, err = o.private(); err != nil {
+		return
+	}
+
 	var en []byte
-	en, err = o.coding.Decode(encrypt)
-	return openssl.RSADecrypt(en, o.privateKey)
+	if en, err = o.coding.Decode(encrypt); err != nil {
+		return
+	}
+	if o.padding == PaddingOAEP && !o.oaepHash.Available() {
+		err = ErrHash
+		return
+	}
+	size := priv.Size()
+	if len(en) == 0 || len(en)%size != 0 {
+		err = ErrCiphertext
+		return
+	}
+
+	for ; len(en) > 0; en = en[size:] {
+		var block []byte
+		if block, err = o.decrypt(priv, en[:size]); err != nil {
+			return
+		}
+		origin = append(origin, block...)
+	}
+	return
+}
+
+func (o *RSA) Sign(origin []byte) (sign string, err error) {
+	var priv *rsa.PrivateKey
+	if priv, err = o.private(); err != nil {
+		return
+	}
+
+	if !o.signHash.Available() {
+		err = ErrHash
+		return
+	}
+	h := o.signHash.New()
+	h.Write(origin)
+
+	var s []byte
+	switch o.signType {
+	case SignPSS:
+		s, err = rsa.SignPSS(rand.Reader, priv, o.signHash, h.Sum(nil), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	default:
+		s, err = rsa.SignPKCS1v15(rand.Reader, priv, o.signHash, h.Sum(nil))
+	}
+	if err != nil {
+		return
+	}
+	sign = o.coding.Encode(s)
+	return
+}
+
+func (o *RSA) Verify(origin []byte, sign string) (err error) {
+	var pub *rsa.PublicKey
+	if pub, err = o.public(); err != nil {
+		return
+	}
+
+	var s []byte
+	if s, err = o.coding.Decode(sign); err != nil {
+		return
+	}
+
+	if !o.signHash.Available() {
+		return ErrHash
+	}
+	h := o.signHash.New()
+	h.Write(origin)
+
+	switch o.signType {
+	case SignPSS:
+		return rsa.VerifyPSS(pub, o.signHash, h.Sum(nil), s, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+	default:
+		return rsa.VerifyPKCS1v15(pub, o.signHash, h.Sum(nil), s)
+	}
+}
+
+// blockSize 单次可加密的明文长度,OAEP搭配长哈希时短密钥可能一个字节都加密不了
+func (o *RSA) blockSize(pub *rsa.PublicKey) (size int, err error) {
+	size = pub.Size() - 11
+	if o.padding == PaddingOAEP {
+		// 未链接或未知的hash调用Size/New会panic
+		if !o.oaepHash.Available() {
+			err = ErrHash
+			return
+		}
+		size = pub.Size() - 2*o.oaepHash.Size() - 2
+	}
+	if size <= 0 {
+		err = ErrKeySize
+	}
+	return
+}
+
+func (o *RSA) encrypt(pub *rsa.PublicKey, origin []byte) ([]byte, error) {
+	if o.padding == PaddingOAEP {
+		return rsa.EncryptOAEP(o.oaepHash.New(), rand.Reader, pub, origin, nil)
+	}
+	return rsa.EncryptPKCS1v15(rand.Reader, pub, origin)
+}
+
+func (o *RSA) decrypt(priv *rsa.PrivateKey, en []byte) ([]byte, error) {
+	if o.padding == PaddingOAEP {
+		return rsa.DecryptOAEP(o.oaepHash.New(), rand.Reader, priv, en, nil)
+	}
+	return rsa.DecryptPKCS1v15(rand.Reader, priv, en)
+}
+
+func (o *RSA) public() (pub *rsa.PublicKey, err error) {
+	block, _ := pem.Decode(o.publicKey)
+	if block == nil {
+		err = ErrKeyFormat
+		return
+	}
+
+	if pub, err = x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return
+	}
+	var key interface{}
+	if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		return
+	}
+	var ok bool
+	if pub, ok = key.(*rsa.PublicKey); !ok {
+		err = ErrKeyType
+	}
+	return
+}
+
+func (o *RSA) private() (priv *rsa.PrivateKey, err error) {
+	block, _ := pem.Decode(o.privateKey)
+	if block == nil {
+		err = ErrKeyFormat
+		return
+	}
+
+	if priv, err = x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return
+	}
+	var key interface{}
+	if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		return
+	}
+	var ok bool
+	if priv, ok = key.(*rsa.PrivateKey); !ok {
+		err = ErrKeyType
+	}
+	return
 }
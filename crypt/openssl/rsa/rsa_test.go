@@ -0,0 +1,126 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/neo532/kratos_kit/crypt"
+)
+
+func genKey(t *testing.T, bits int) (pub, priv string) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkix, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(key)
+	pub = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	priv = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	return
+}
+
+func TestEncrypt(t *testing.T) {
+	pub, priv := genKey(t, 1024)
+	long := strings.Repeat("abcdefghijklmnopqrstuvwxyz1234567890", 10)
+
+	for _, padding := range []string{PaddingPKCS1v15, PaddingOAEP} {
+		cr := New(
+			WithPublicKey(pub),
+			WithPrivateKey(priv),
+			WithPadding(padding),
+		)
+		for _, cnt := range []string{"", "abc", long} {
+			en, err := cr.Encrypt([]byte(cnt))
+			if err != nil {
+				t.Fatalf("%s Encrypt(%d): %+v", padding, len(cnt), err)
+			}
+			origin, err := cr.Decrypt(en)
+			if err != nil || string(origin) != cnt {
+				t.Errorf("%s origin, err:\t%+v,%+v", padding, string(origin), err)
+			}
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	pub, priv := genKey(t, 1024)
+	cnt := []byte("app_id=1&biz_content=abc&timestamp=2023-09-25")
+
+	for _, signType := range []string{SignPKCS1v15, SignPSS} {
+		for _, hash := range []crypto.Hash{crypto.SHA256, crypto.SHA1} {
+			cr := New(
+				WithPublicKey(pub),
+				WithPrivateKey(priv),
+				WithSignType(signType),
+				WithSignHash(hash),
+			)
+			sign, err := cr.Sign(cnt)
+			if err != nil {
+				t.Fatalf("%s-%v Sign: %+v", signType, hash, err)
+			}
+			if err = cr.Verify(cnt, sign); err != nil {
+				t.Errorf("%s-%v Verify: %+v", signType, hash, err)
+			}
+			if err = cr.Verify(append(cnt, '1'), sign); err == nil {
+				t.Errorf("%s-%v Verify tampered content", signType, hash)
+			}
+		}
+	}
+}
+
+func TestOAEPHash(t *testing.T) {
+	pub, priv := genKey(t, 1024)
+
+	cr := New(WithPublicKey(pub), WithPrivateKey(priv), WithPadding(PaddingOAEP), WithOAEPHash(crypto.SHA1))
+	en, err := cr.Encrypt([]byte("abc"))
+	if err != nil {
+		t.Fatalf("Encrypt: %+v", err)
+	}
+	if origin, err := cr.Decrypt(en); err != nil || string(origin) != "abc" {
+		t.Errorf("origin, err:\t%+v,%+v", string(origin), err)
+	}
+
+	// 1024位密钥搭配SHA512时单段可加密长度为负
+	cr = New(WithPublicKey(pub), WithPadding(PaddingOAEP), WithOAEPHash(crypto.SHA512))
+	if _, err = cr.Encrypt([]byte("abc")); !errors.Is(err, ErrKeySize) {
+		t.Errorf("Encrypt with SHA512: %+v", err)
+	}
+}
+
+// 未链接的hash返回错误而不是panic
+func TestUnavailableHash(t *testing.T) {
+	pub, priv := genKey(t, 1024)
+	for _, hash := range []crypto.Hash{crypto.MD4, 0} {
+		cr := New(WithPublicKey(pub), WithPrivateKey(priv), WithPadding(PaddingOAEP), WithOAEPHash(hash), WithSignHash(hash))
+		if _, err := cr.Encrypt([]byte("abc")); !errors.Is(err, ErrHash) {
+			t.Errorf("%v Encrypt: %+v", hash, err)
+		}
+		if _, err := cr.Decrypt(strings.Repeat("A", 172)); !errors.Is(err, ErrHash) {
+			t.Errorf("%v Decrypt: %+v", hash, err)
+		}
+		if _, err := cr.Sign([]byte("abc")); !errors.Is(err, ErrHash) {
+			t.Errorf("%v Sign: %+v", hash, err)
+		}
+		if err := cr.Verify([]byte("abc"), "AAAA"); !errors.Is(err, ErrHash) {
+			t.Errorf("%v Verify: %+v", hash, err)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	pub, _ := genKey(t, 512)
+	conf := &crypt.Config{PublicKey: crypt.Key{Inline: pub}}
+	if _, err := Build(conf); err != nil {
+		t.Fatalf("Build PKCS1v15: %+v", err)
+	}
+	conf.Padding = PaddingOAEP
+	if _, err := Build(conf); !errors.Is(err, ErrKeySize) {
+		t.Errorf("Build OAEP with a 512-bit key: %+v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// RFC 9106 4.节第二组推荐参数(内存受限时): t=3, m=64MiB, p=4
+const (
+	DefaultArgon2idTime    = 3
+	DefaultArgon2idMemory  = 64 * 1024
+	DefaultArgon2idThreads = 4
+
+	argon2idSaltLen = 16
+	argon2idKeyLen  = 32
+)
+
+// Argon2id $argon2id$v=19$m=65536,t=3,p=4$salt$hash
+type Argon2id struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+}
+
+// NewArgon2id memory单位为KiB
+func NewArgon2id(time, memory uint32, threads uint8) *Argon2id {
+	return &Argon2id{time: time, memory: memory, threads: threads}
+}
+
+func (o *Argon2id) Match(id string) bool {
+	return id == "argon2id"
+}
+
+func (o *Argon2id) Hash(password []byte) (encoded string, err error) {
+	var s []byte
+	if s, err = salt(argon2idSaltLen); err != nil {
+		return
+	}
+	key := argon2.IDKey(password, s, o.time, o.memory, o.threads, argon2idKeyLen)
+	encoded = fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, o.memory, o.time, o.threads, b64.EncodeToString(s), b64.EncodeToString(key))
+	return
+}
+
+func (o *Argon2id) Verify(password []byte, encoded string) (needsRehash bool, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		err = ErrFormat
+		return
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		err = ErrFormat
+		return
+	}
+	var time, memory uint32
+	var threads uint8
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil || time == 0 || threads == 0 {
+		err = ErrFormat
+		return
+	}
+	var s, key []byte
+	if s, err = b64.DecodeString(parts[4]); err != nil {
+		err = ErrFormat
+		return
+	}
+	if key, err = b64.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		err = ErrFormat
+		return
+	}
+
+	other := argon2.IDKey(password, s, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		err = ErrMismatch
+		return
+	}
+	needsRehash = time != o.time || memory != o.memory || threads != o.threads ||
+		len(s) != argon2idSaltLen || len(key) != argon2idKeyLen
+	return
+}
@@ -0,0 +1,53 @@
+package password
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+const DefaultBcryptCost = bcrypt.DefaultCost
+
+// Bcrypt 使用bcrypt自身的$2a$cost$salthash格式.
+// 生成哈希时口令超过72字节返回ErrTooLong;校验时兼容其他实现截断生成的旧哈希,只比较前72字节
+type Bcrypt struct {
+	cost int
+}
+
+func NewBcrypt(cost int) *Bcrypt {
+	return &Bcrypt{cost: cost}
+}
+
+func (o *Bcrypt) Match(id string) bool {
+	return id == "2a" || id == "2b" || id == "2y"
+}
+
+func (o *Bcrypt) Hash(password []byte) (encoded string, err error) {
+	var b []byte
+	if b, err = bcrypt.GenerateFromPassword(password, o.cost); err != nil {
+		if err == bcrypt.ErrPasswordTooLong {
+			err = ErrTooLong
+		}
+		return
+	}
+	encoded = string(b)
+	return
+}
+
+// Verify bcrypt.CompareHashAndPassword内部为常量时间比较
+func (o *Bcrypt) Verify(password []byte, encoded string) (needsRehash bool, err error) {
+	if err = bcrypt.CompareHashAndPassword([]byte(encoded), password); err != nil {
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			err = ErrMismatch
+		} else {
+			err = ErrFormat
+		}
+		return
+	}
+
+	var cost int
+	if cost, err = bcrypt.Cost([]byte(encoded)); err != nil {
+		err = ErrFormat
+		return
+	}
+	needsRehash = cost != o.cost
+	return
+}
@@ -0,0 +1,97 @@
+package password
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMismatch  = errors.New("password: password does not match")
+	ErrFormat    = errors.New("password: invalid encoded hash")
+	ErrAlgorithm = errors.New("password: unsupported algorithm")
+	ErrTooLong   = errors.New("password: password is too long for the algorithm")
+)
+
+// Hasher 口令哈希算法,编码为PHC格式: $id$param=value,...$salt$hash
+type Hasher interface {
+	// Match 是否能处理该PHC id
+	Match(id string) bool
+	Hash(password []byte) (encoded string, err error)
+	// Verify 校验口令,needsRehash表示encoded的参数与当前配置不一致
+	Verify(password []byte, encoded string) (needsRehash bool, err error)
+}
+
+// Password 用当前算法生成哈希,校验时按哈希中的算法标识选用当前或旧算法,
+// 使用旧算法或旧参数的哈希在校验成功后返回needsRehash,调用方应在登录时重新生成.
+type Password struct {
+	hasher Hasher
+	legacy []Hasher
+}
+
+type opt func(o *Password)
+
+// WithHasher 生成哈希使用的算法,默认Argon2id
+func WithHasher(h Hasher) opt {
+	return func(o *Password) {
+		o.hasher = h
+	}
+}
+
+// WithLegacy 仅用于校验的算法,默认包含默认参数的bcrypt和scrypt
+func WithLegacy(hs ...Hasher) opt {
+	return func(o *Password) {
+		o.legacy = hs
+	}
+}
+
+func New(opts ...opt) (os *Password) {
+	os = &Password{
+		hasher: NewArgon2id(DefaultArgon2idTime, DefaultArgon2idMemory, DefaultArgon2idThreads),
+		legacy: []Hasher{
+			NewBcrypt(DefaultBcryptCost),
+			NewScrypt(DefaultScryptN, DefaultScryptR, DefaultScryptP),
+		},
+	}
+	for _, fn := range opts {
+		fn(os)
+	}
+	return os
+}
+
+func (o *Password) Hash(password string) (encoded string, err error) {
+	return o.hasher.Hash([]byte(password))
+}
+
+// Verify 口令不匹配时返回ErrMismatch
+func (o *Password) Verify(password, encoded string) (needsRehash bool, err error) {
+	id := phcID(encoded)
+	if o.hasher.Match(id) {
+		return o.hasher.Verify([]byte(password), encoded)
+	}
+	for _, h := range o.legacy {
+		if h.Match(id) {
+			_, err = h.Verify([]byte(password), encoded)
+			return err == nil, err
+		}
+	}
+	err = ErrAlgorithm
+	return
+}
+
+func phcID(encoded string) string {
+	if !strings.HasPrefix(encoded, "$") {
+		return ""
+	}
+	id, _, _ := strings.Cut(encoded[1:], "$")
+	return id
+}
+
+func salt(n int) (s []byte, err error) {
+	s = make([]byte, n)
+	_, err = rand.Read(s)
+	return
+}
+
+var b64 = base64.RawStdEncoding
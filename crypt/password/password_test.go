@@ -0,0 +1,83 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+var (
+	fastArgon2id = NewArgon2id(1, 1024, 1)
+	fastScrypt   = NewScrypt(1024, 8, 1)
+	fastBcrypt   = NewBcrypt(4)
+)
+
+func TestVerify(t *testing.T) {
+	for _, h := range []Hasher{fastArgon2id, fastScrypt, fastBcrypt} {
+		p := New(WithHasher(h))
+		encoded, err := p.Hash("correct horse battery staple")
+		if err != nil {
+			t.Fatalf("Hash: %+v", err)
+		}
+		if again, _ := p.Hash("correct horse battery staple"); again == encoded {
+			t.Errorf("salt is reused: %s", encoded)
+		}
+
+		needsRehash, err := p.Verify("correct horse battery staple", encoded)
+		if err != nil || needsRehash {
+			t.Errorf("%s Verify:\t%+v,%+v", encoded, needsRehash, err)
+		}
+		if _, err = p.Verify("Correct horse battery staple", encoded); err != ErrMismatch {
+			t.Errorf("%s Verify wrong password: %+v", encoded, err)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	old := New(WithHasher(fastArgon2id))
+	encoded, _ := old.Hash("secret")
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("encoded:\t%s", encoded)
+	}
+
+	p := New(
+		WithHasher(NewArgon2id(2, 1024, 1)),
+		WithLegacy(fastScrypt, fastBcrypt),
+	)
+	if needsRehash, err := p.Verify("secret", encoded); err != nil || !needsRehash {
+		t.Errorf("outdated parameters:\t%+v,%+v", needsRehash, err)
+	}
+
+	for _, h := range []Hasher{fastScrypt, fastBcrypt} {
+		encoded, _ = h.Hash([]byte("secret"))
+		if needsRehash, err := p.Verify("secret", encoded); err != nil || !needsRehash {
+			t.Errorf("%s legacy algorithm:\t%+v,%+v", encoded, needsRehash, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	p := New(WithHasher(fastArgon2id), WithLegacy(fastScrypt, fastBcrypt))
+	for encoded, want := range map[string]error{
+		"":                                     ErrAlgorithm,
+		"5f4dcc3b5aa765d61d8327deb882cf99":     ErrAlgorithm,
+		"$pbkdf2-sha256$i=1000$c2FsdA$aGFzaA":  ErrAlgorithm,
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA": ErrFormat,
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA": ErrFormat,
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$aGFzaA": ErrFormat,
+		"$scrypt$ln=99,r=8,p=1$c2FsdA$aGFzaA":         ErrFormat,
+		"$2a$04$short":                                ErrFormat,
+	} {
+		if _, err := p.Verify("secret", encoded); err != want {
+			t.Errorf("%s: %+v", encoded, err)
+		}
+	}
+}
+
+func TestBcryptTooLong(t *testing.T) {
+	if _, err := fastBcrypt.Hash([]byte(strings.Repeat("a", 73))); err != ErrTooLong {
+		t.Errorf("73 bytes: %+v", err)
+	}
+	if _, err := fastBcrypt.Hash([]byte(strings.Repeat("a", 72))); err != nil {
+		t.Errorf("72 bytes: %+v", err)
+	}
+}
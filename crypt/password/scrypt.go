@@ -0,0 +1,84 @@
+package password
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	DefaultScryptN = 32768
+	DefaultScryptR = 8
+	DefaultScryptP = 1
+
+	scryptSaltLen = 16
+	scryptKeyLen  = 32
+)
+
+// Scrypt $scrypt$ln=15,r=8,p=1$salt$hash,ln为log2(N)
+type Scrypt struct {
+	n int
+	r int
+	p int
+}
+
+// NewScrypt n须为2的幂
+func NewScrypt(n, r, p int) *Scrypt {
+	return &Scrypt{n: n, r: r, p: p}
+}
+
+func (o *Scrypt) Match(id string) bool {
+	return id == "scrypt"
+}
+
+func (o *Scrypt) Hash(password []byte) (encoded string, err error) {
+	var s, key []byte
+	if s, err = salt(scryptSaltLen); err != nil {
+		return
+	}
+	if key, err = scrypt.Key(password, s, o.n, o.r, o.p, scryptKeyLen); err != nil {
+		return
+	}
+	encoded = fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		bits.TrailingZeros(uint(o.n)), o.r, o.p, b64.EncodeToString(s), b64.EncodeToString(key))
+	return
+}
+
+func (o *Scrypt) Verify(password []byte, encoded string) (needsRehash bool, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		err = ErrFormat
+		return
+	}
+
+	var ln, r, p int
+	if _, err = fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &ln, &r, &p); err != nil || ln <= 0 || ln >= 32 {
+		err = ErrFormat
+		return
+	}
+	var s, key []byte
+	if s, err = b64.DecodeString(parts[3]); err != nil {
+		err = ErrFormat
+		return
+	}
+	if key, err = b64.DecodeString(parts[4]); err != nil || len(key) == 0 {
+		err = ErrFormat
+		return
+	}
+
+	var other []byte
+	if other, err = scrypt.Key(password, s, 1<<ln, r, p, len(key)); err != nil {
+		err = ErrFormat
+		return
+	}
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		err = ErrMismatch
+		return
+	}
+	needsRehash = 1<<ln != o.n || r != o.r || p != o.p ||
+		len(s) != scryptSaltLen || len(key) != scryptKeyLen
+	return
+}
@@ -0,0 +1,45 @@
+package crypt
+
+import (
+	"sort"
+	"strings"
+)
+
+// Canonicalize 把参数按key升序拼接为k1=v1&k2=v2,跳过空值和skip中的key(如sign、sign_type),
+// 是微信支付、支付宝等接口签名前的通用规范化方式.
+func Canonicalize(m map[string]string, skip ...string) string {
+	skipped := make(map[string]struct{}, len(skip))
+	for _, k := range skip {
+		skipped[k] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k, v := range m {
+		if _, ok := skipped[k]; ok || v == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString("&")
+		}
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(m[k])
+	}
+	return b.String()
+}
+
+// SignMap 规范化参数后签名
+func SignMap(s ISigner, m map[string]string, skip ...string) (sign string, err error) {
+	return s.Sign([]byte(Canonicalize(m, skip...)))
+}
+
+// VerifyMap 规范化参数后验签
+func VerifyMap(s ISigner, m map[string]string, sign string, skip ...string) (err error) {
+	return s.Verify([]byte(Canonicalize(m, skip...)), sign)
+}
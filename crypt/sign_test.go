@@ -0,0 +1,18 @@
+package crypt
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	m := map[string]string{
+		"mch_id":    "10000100",
+		"appid":     "wxd930ea5d5a258f4f",
+		"body":      "test",
+		"nonce_str": "ibuaiVcKdpRxkhJA",
+		"device":    "",
+		"sign":      "xxx",
+	}
+	want := "appid=wxd930ea5d5a258f4f&body=test&mch_id=10000100&nonce_str=ibuaiVcKdpRxkhJA"
+	if got := Canonicalize(m, "sign"); got != want {
+		t.Errorf("Canonicalize:\t%s", got)
+	}
+}
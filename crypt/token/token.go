@@ -0,0 +1,338 @@
+package token
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/crypt/encoding/rawurl"
+	"github.com/neo532/kratos_kit/crypt/encoding/std"
+	"github.com/neo532/kratos_kit/crypt/hmac"
+	"github.com/neo532/kratos_kit/crypt/openssl/rsa"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	// AlgEncrypt 加密模式,claims由crypt.ICrypt加密,建议使用gcm等认证加密算法
+	AlgEncrypt = "ENC"
+)
+
+var (
+	ErrFormat       = errors.New("token: invalid format")
+	ErrNoActiveKey  = errors.New("token: no active key")
+	ErrUnknownKey   = errors.New("token: unknown key id")
+	ErrAlgorithm    = errors.New("token: algorithm does not match the key")
+	ErrSignature    = errors.New("token: signature verification failed")
+	ErrExpired      = errors.New("token: token is expired")
+	ErrNotValidYet  = errors.New("token: token is not valid yet")
+	ErrIssuer       = errors.New("token: issuer does not match")
+	ErrAudience     = errors.New("token: audience does not match")
+	ErrMissingClaim = errors.New("token: exp is required")
+)
+
+// Claims JWT的标准字段,自定义数据放在data中
+type Claims[T any] struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	Data      T        `json:"data"`
+}
+
+// Audience JWT中aud可以是字符串或数组,统一解析为数组
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(b []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(b, &s); err == nil {
+		*a = Audience{s}
+		return
+	}
+	return json.Unmarshal(b, (*[]string)(a))
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type key struct {
+	alg    string
+	signer crypt.ISigner
+	crypt  crypt.ICrypt
+	coding crypt.IEncoding
+}
+
+type options struct {
+	keys     map[string]*key
+	active   string
+	issuer   string
+	audience string
+	ttl      time.Duration
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+type opt func(o *options)
+
+// WithSigner 注册签名密钥,签名值须为无填充的base64url才与JWT兼容
+func WithSigner(kid, alg string, s crypt.ISigner) opt {
+	return func(o *options) {
+		o.keys[kid] = &key{alg: alg, signer: s}
+	}
+}
+
+// WithHS256 注册HMAC-SHA256密钥
+func WithHS256(kid, secret string) opt {
+	return WithSigner(kid, AlgHS256, hmac.New(
+		hmac.WithKey(secret),
+		hmac.WithEncoding(rawurl.New()),
+	))
+}
+
+// WithRS256 注册RSA-SHA256密钥,只校验的服务可以只传公钥
+func WithRS256(kid, publicKey, privateKey string) opt {
+	return WithSigner(kid, AlgRS256, rsa.New(
+		rsa.WithPublicKey(publicKey),
+		rsa.WithPrivateKey(privateKey),
+		rsa.WithEncoding(rawurl.New()),
+	))
+}
+
+// WithCrypt 注册加密密钥,token内容对持有者不可见,cr需使用默认的std编码
+func WithCrypt(kid string, cr crypt.ICrypt) opt {
+	return WithCryptEncoding(kid, cr, std.New())
+}
+
+// WithCryptEncoding 注册加密密钥,coding为cr的密文编码,
+// token中的密文统一转为无填充的base64url,可直接放在URL和Cookie中
+func WithCryptEncoding(kid string, cr crypt.ICrypt, coding crypt.IEncoding) opt {
+	return func(o *options) {
+		o.keys[kid] = &key{alg: AlgEncrypt, crypt: cr, coding: coding}
+	}
+}
+
+// WithActive 签发使用的key id,旧密钥保留注册即可继续校验已签发的token
+func WithActive(kid string) opt {
+	return func(o *options) {
+		o.active = kid
+	}
+}
+
+// WithIssuer 签发时写入iss,校验时要求一致
+func WithIssuer(iss string) opt {
+	return func(o *options) {
+		o.issuer = iss
+	}
+}
+
+// WithAudience 签发时写入aud,校验时要求aud包含该值
+func WithAudience(aud string) opt {
+	return func(o *options) {
+		o.audience = aud
+	}
+}
+
+// WithTTL 有效期,默认2小时
+func WithTTL(ttl time.Duration) opt {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithLeeway 校验exp/nbf/iat时容忍的时钟偏差
+func WithLeeway(leeway time.Duration) opt {
+	return func(o *options) {
+		o.leeway = leeway
+	}
+}
+
+// WithClock 当前时间,仅测试时替换
+func WithClock(now func() time.Time) opt {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// Token 签发与校验紧凑格式的token:
+// 签名模式为JWT(header.claims.signature),加密模式为header.ciphertext,
+// 加密模式的明文为header.claims,解密后比对header,alg/kid被替换时校验失败
+type Token[T any] struct {
+	options
+}
+
+func New[T any](opts ...opt) (os *Token[T]) {
+	os = &Token[T]{
+		options: options{
+			keys: make(map[string]*key),
+			ttl:  2 * time.Hour,
+			now:  time.Now,
+		},
+	}
+	for _, fn := range opts {
+		fn(&os.options)
+	}
+	return os
+}
+
+// Issue 按配置的iss/aud/ttl签发
+func (o *Token[T]) Issue(data T) (token string, err error) {
+	now := o.now()
+	claims := &Claims[T]{
+		Issuer:    o.issuer,
+		ExpiresAt: now.Add(o.ttl).Unix(),
+		IssuedAt:  now.Unix(),
+		Data:      data,
+	}
+	if o.audience != "" {
+		claims.Audience = Audience{o.audience}
+	}
+	return o.IssueClaims(claims)
+}
+
+// IssueClaims 按给定的claims签发,不做修改
+func (o *Token[T]) IssueClaims(claims *Claims[T]) (token string, err error) {
+	k, ok := o.keys[o.active]
+	if !ok {
+		err = ErrNoActiveKey
+		return
+	}
+
+	var h, c []byte
+	if h, err = json.Marshal(&header{Alg: k.alg, Typ: "JWT", Kid: o.active}); err != nil {
+		return
+	}
+	if c, err = json.Marshal(claims); err != nil {
+		return
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(h) + "."
+	if k.crypt != nil {
+		var en string
+		if en, err = k.crypt.Encrypt(append([]byte(token), c...)); err != nil {
+			return
+		}
+		var b []byte
+		if b, err = k.coding.Decode(en); err != nil {
+			return
+		}
+		token += base64.RawURLEncoding.EncodeToString(b)
+		return
+	}
+
+	token += base64.RawURLEncoding.EncodeToString(c)
+	var sign string
+	if sign, err = k.signer.Sign([]byte(token)); err != nil {
+		return
+	}
+	token += "." + sign
+	return
+}
+
+// Parse 校验签名或解密,并校验exp/nbf/iat/iss/aud
+func (o *Token[T]) Parse(token string) (claims *Claims[T], err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 && len(parts) != 3 {
+		err = ErrFormat
+		return
+	}
+
+	var h header
+	var b []byte
+	if b, err = base64.RawURLEncoding.DecodeString(parts[0]); err != nil {
+		err = ErrFormat
+		return
+	}
+	if err = json.Unmarshal(b, &h); err != nil {
+		err = ErrFormat
+		return
+	}
+
+	// 没有kid的token使用当前密钥校验
+	kid := h.Kid
+	if kid == "" {
+		kid = o.active
+	}
+	k, ok := o.keys[kid]
+	if !ok {
+		err = ErrUnknownKey
+		return
+	}
+	if h.Alg != k.alg || (k.crypt != nil) != (len(parts) == 2) {
+		err = ErrAlgorithm
+		return
+	}
+
+	if k.crypt != nil {
+		if b, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+			err = ErrFormat
+			return
+		}
+		if b, err = k.crypt.Decrypt(k.coding.Encode(b)); err != nil {
+			err = ErrSignature
+			return
+		}
+		var found bool
+		var h []byte
+		if h, b, found = bytes.Cut(b, []byte(".")); !found || string(h) != parts[0] {
+			err = ErrSignature
+			return
+		}
+	} else {
+		if err = k.signer.Verify([]byte(parts[0]+"."+parts[1]), parts[2]); err != nil {
+			err = ErrSignature
+			return
+		}
+		if b, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+			err = ErrFormat
+			return
+		}
+	}
+
+	c := &Claims[T]{}
+	if err = json.Unmarshal(b, c); err != nil {
+		err = ErrFormat
+		return
+	}
+	if err = o.validate(c); err != nil {
+		return
+	}
+	claims = c
+	return
+}
+
+func (o *Token[T]) validate(c *Claims[T]) (err error) {
+	now := o.now()
+	leeway := int64(o.leeway / time.Second)
+	switch {
+	case c.ExpiresAt == 0:
+		return ErrMissingClaim
+	case now.Unix() > c.ExpiresAt+leeway:
+		return ErrExpired
+	case c.NotBefore != 0 && now.Unix()+leeway < c.NotBefore:
+		return ErrNotValidYet
+	case c.IssuedAt != 0 && now.Unix()+leeway < c.IssuedAt:
+		return ErrNotValidYet
+	case o.issuer != "" && c.Issuer != o.issuer:
+		return ErrIssuer
+	}
+
+	if o.audience == "" {
+		return
+	}
+	for _, aud := range c.Audience {
+		if aud == o.audience {
+			return
+		}
+	}
+	return ErrAudience
+}
@@ -0,0 +1,146 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neo532/kratos_kit/crypt/encoding/rawurl"
+	"github.com/neo532/kratos_kit/crypt/openssl/gcm"
+)
+
+type session struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+func TestIssue(t *testing.T) {
+	priv, _ := rsa.GenerateKey(rand.Reader, 1024)
+	privPem := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	pubPem := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&priv.PublicKey)}))
+
+	for _, opt := range []opt{
+		WithHS256("hs", "rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"),
+		WithRS256("rs", pubPem, privPem),
+		WithCrypt("enc", gcm.New(gcm.WithKey("rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"))),
+		WithCryptEncoding("enc_rawurl", gcm.New(gcm.WithKey("rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"), gcm.WithEncoding(rawurl.New())), rawurl.New()),
+	} {
+		tk := New[session](opt, WithIssuer("kit"), WithAudience("app"))
+		for kid := range tk.keys {
+			tk.active = kid
+		}
+
+		token, err := tk.Issue(session{UserID: 1, Role: "admin"})
+		if err != nil {
+			t.Fatalf("%s Issue: %+v", tk.active, err)
+		}
+		if strings.ContainsAny(token, "+/=") {
+			t.Errorf("%s is not base64url: %s", tk.active, token)
+		}
+		claims, err := tk.Parse(token)
+		if err != nil {
+			t.Fatalf("%s Parse: %+v", tk.active, err)
+		}
+		if claims.Data.UserID != 1 || claims.Data.Role != "admin" || claims.Issuer != "kit" {
+			t.Errorf("%s claims:\t%+v", tk.active, claims)
+		}
+
+		tampered := token[:len(token)-2] + "AA"
+		if _, err = tk.Parse(tampered); err != ErrSignature {
+			t.Errorf("%s tampered: %+v", tk.active, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tk := New[session](
+		WithHS256("k1", "secret"),
+		WithActive("k1"),
+		WithIssuer("kit"),
+		WithAudience("app"),
+		WithTTL(time.Minute),
+		WithLeeway(5*time.Second),
+		WithClock(func() time.Time { return now }),
+	)
+	token, _ := tk.Issue(session{UserID: 1})
+
+	for name, c := range map[string]struct {
+		at  time.Time
+		err error
+	}{
+		"valid":  {now.Add(time.Minute), nil},
+		"leeway": {now.Add(time.Minute + 5*time.Second), nil},
+		"expire": {now.Add(time.Minute + 6*time.Second), ErrExpired},
+		"skew":   {now.Add(-6 * time.Second), ErrNotValidYet},
+	} {
+		tk.now = func() time.Time { return c.at }
+		if _, err := tk.Parse(token); err != c.err {
+			t.Errorf("%s: %+v", name, err)
+		}
+	}
+	tk.now = func() time.Time { return now }
+
+	for name, c := range map[string]struct {
+		tk  *Token[session]
+		err error
+	}{
+		"issuer":   {New[session](WithHS256("k1", "secret"), WithIssuer("other"), WithClock(tk.now)), ErrIssuer},
+		"audience": {New[session](WithHS256("k1", "secret"), WithAudience("other"), WithClock(tk.now)), ErrAudience},
+		"secret":   {New[session](WithHS256("k1", "other"), WithClock(tk.now)), ErrSignature},
+		"kid":      {New[session](WithHS256("k2", "secret"), WithClock(tk.now)), ErrUnknownKey},
+		"alg":      {New[session](WithCrypt("k1", gcm.New(gcm.WithKey("rW@vM2UlXKGh2V%!"))), WithClock(tk.now)), ErrAlgorithm},
+	} {
+		if _, err := c.tk.Parse(token); err != c.err {
+			t.Errorf("%s: %+v", name, err)
+		}
+	}
+}
+
+// 加密模式的header参与认证,不能替换为同一密钥注册的其他kid
+func TestCryptHeader(t *testing.T) {
+	cr := gcm.New(gcm.WithKey("rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"))
+	tk := New[session](WithCrypt("k1", cr), WithCrypt("k2", cr), WithActive("k1"))
+	token, err := tk.Issue(session{UserID: 1})
+	if err != nil {
+		t.Fatalf("Issue: %+v", err)
+	}
+	if _, err = tk.Parse(token); err != nil {
+		t.Fatalf("Parse: %+v", err)
+	}
+
+	h := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ENC","typ":"JWT","kid":"k2"}`))
+	_, en, _ := strings.Cut(token, ".")
+	if _, err = tk.Parse(h + "." + en); err != ErrSignature {
+		t.Errorf("Parse with a replaced header: %+v", err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	old := New[session](WithHS256("k1", "secret1"), WithActive("k1"))
+	token, _ := old.Issue(session{UserID: 1})
+
+	tk := New[session](WithHS256("k1", "secret1"), WithHS256("k2", "secret2"), WithActive("k2"))
+	if _, err := tk.Parse(token); err != nil {
+		t.Errorf("token signed by the old key: %+v", err)
+	}
+	if token, _ = tk.Issue(session{UserID: 1}); !strings.HasPrefix(token, "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCIsImtpZCI6ImsyIn0.") {
+		t.Errorf("header:\t%s", token)
+	}
+}
+
+// jwt.io的示例token,确认HS256签名与JWT一致
+func TestJWTCompatible(t *testing.T) {
+	token := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9" +
+		".eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ" +
+		".SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+	tk := New[session](WithHS256("k1", "your-256-bit-secret"), WithActive("k1"))
+	if _, err := tk.Parse(token); err != ErrMissingClaim {
+		t.Errorf("Parse: %+v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package orm
+
+/*
+ * @abstract 字段透明加密,以及用于等值查询的盲索引
+ */
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/neo532/kratos_kit/crypt"
+)
+
+var (
+	ErrCryptNotRegistered      = errors.New("orm: crypt is not registered")
+	ErrBlindIndexNotRegistered = errors.New("orm: blind index signer is not registered")
+)
+
+var (
+	cryptLock  sync.RWMutex
+	fieldCrypt crypt.ICrypt
+	blindIndex crypt.ISigner
+)
+
+// RegisterCrypt 注册EncryptedString使用的加密算法,需在读写数据库之前调用.
+// 更换密钥时可注册keyring,旧数据仍可解密.
+func RegisterCrypt(cr crypt.ICrypt) {
+	cryptLock.Lock()
+	defer cryptLock.Unlock()
+	fieldCrypt = cr
+}
+
+// RegisterBlindIndex 注册盲索引使用的签名算法,需为确定性算法,如HMAC
+func RegisterBlindIndex(s crypt.ISigner) {
+	cryptLock.Lock()
+	defer cryptLock.Unlock()
+	blindIndex = s
+}
+
+func getCrypt() (cr crypt.ICrypt, err error) {
+	cryptLock.RLock()
+	defer cryptLock.RUnlock()
+	if cr = fieldCrypt; cr == nil {
+		err = ErrCryptNotRegistered
+	}
+	return
+}
+
+// EncryptedString 写入时加密,读取时解密,空串原样存储.
+// 密文比明文长,列需预留足够长度.
+type EncryptedString string
+
+func (s EncryptedString) Value() (v driver.Value, err error) {
+	if s == "" {
+		return "", nil
+	}
+
+	var cr crypt.ICrypt
+	if cr, err = getCrypt(); err != nil {
+		return
+	}
+	return cr.Encrypt([]byte(s))
+}
+
+func (s *EncryptedString) Scan(src interface{}) (err error) {
+	var en string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		en = v
+	case []byte:
+		en = string(v)
+	default:
+		return fmt.Errorf("orm: cannot scan %T into EncryptedString", src)
+	}
+	if en == "" {
+		*s = ""
+		return
+	}
+
+	var cr crypt.ICrypt
+	if cr, err = getCrypt(); err != nil {
+		return
+	}
+	var origin []byte
+	if origin, err = cr.Decrypt(en); err != nil {
+		return
+	}
+	*s = EncryptedString(origin)
+	return
+}
+
+// BlindIndex 计算明文的盲索引,写入时存到伴随列,查询时用WhereBlindIndex.
+// 需要忽略大小写等的场景,调用前自行归一化明文.
+func BlindIndex(origin string) (index string, err error) {
+	cryptLock.RLock()
+	s := blindIndex
+	cryptLock.RUnlock()
+	if s == nil {
+		err = ErrBlindIndexNotRegistered
+		return
+	}
+	return s.Sign([]byte(origin))
+}
+
+// WhereBlindIndex 按盲索引等值查询加密列,如
+// dbs.Read(c).Scopes(orm.WhereBlindIndex("phone_index", phone)).First(&user)
+func WhereBlindIndex(column, origin string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		index, err := BlindIndex(origin)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		return db.Where(clause.Eq{Column: column, Value: index})
+	}
+}
@@ -0,0 +1,56 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	klog "github.com/go-kratos/kratos/v2/log"
+
+	"github.com/neo532/kratos_kit/crypt/hmac"
+	"github.com/neo532/kratos_kit/crypt/openssl/gcm"
+)
+
+type user struct {
+	ID         int64
+	Phone      EncryptedString
+	PhoneIndex string `gorm:"index"`
+}
+
+func TestEncryptedString(t *testing.T) {
+	RegisterCrypt(gcm.New(gcm.WithKey("rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc")))
+	RegisterBlindIndex(hmac.New(hmac.WithKey("Kq0%4bX!z8Vd3@Lm")))
+
+	db := New(t.Name(), sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), WithLogger(klog.DefaultLogger))
+	if db.Err != nil {
+		t.Fatalf("open sqlite: %+v", db.Err)
+	}
+	dbs := News(db, db)
+	c := context.Background()
+	if err := dbs.Write(c).AutoMigrate(&user{}); err != nil {
+		t.Fatalf("AutoMigrate: %+v", err)
+	}
+
+	phone := "13800138000"
+	index, err := BlindIndex(phone)
+	if err != nil {
+		t.Fatalf("BlindIndex: %+v", err)
+	}
+	if err = dbs.Write(c).Create(&user{Phone: EncryptedString(phone), PhoneIndex: index}).Error; err != nil {
+		t.Fatalf("Create: %+v", err)
+	}
+
+	var raw string
+	dbs.Read(c).Model(&user{}).Select("phone").Scan(&raw)
+	if raw == "" || raw == phone {
+		t.Errorf("phone is not encrypted: %s", raw)
+	}
+
+	var u user
+	if err = dbs.Read(c).Scopes(WhereBlindIndex("phone_index", phone)).First(&u).Error; err != nil {
+		t.Fatalf("First: %+v", err)
+	}
+	if string(u.Phone) != phone {
+		t.Errorf("phone:\t%s", u.Phone)
+	}
+}
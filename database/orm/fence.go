@@ -0,0 +1,60 @@
+package orm
+
+/*
+ * @abstract 配合分布式锁的fencing token,拒绝过期持有者的写入
+ */
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrStaleFence = errors.New("orm: stale fence token")
+	ErrNoRecord   = errors.New("orm: no record matches the conditions")
+)
+
+// Fence 为写操作加上 WHERE column <= fence 的条件,
+// 已被更大token写过的记录不会被更新,同一持有者在租约内可多次写入.写入时需同时把column更新为fence.
+func Fence(column string, fence int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Lte{Column: column, Value: fence})
+	}
+}
+
+// UpdatesWithFence 带fencing token更新,同时把column写为fence.
+// db需已指定Model和条件:条件匹配不到记录时返回ErrNoRecord,
+// 记录存在但已被更大的token写过时返回ErrStaleFence.
+func UpdatesWithFence(db *gorm.DB, column string, fence int64, values map[string]interface{}) (err error) {
+	// 新会话,后续的更新和计数互不影响各自的条件
+	db = db.Session(&gorm.Session{})
+
+	vs := make(map[string]interface{}, len(values)+1)
+	for k, v := range values {
+		vs[k] = v
+	}
+	vs[column] = fence
+
+	tx := db.Scopes(Fence(column, fence)).Updates(vs)
+	if err = tx.Error; err != nil {
+		return
+	}
+	if tx.RowsAffected > 0 {
+		return
+	}
+
+	// MySQL的RowsAffected不含值未变化的行,需再确认是否满足fence条件
+	var n int64
+	if err = db.Scopes(Fence(column, fence)).Count(&n).Error; err != nil || n > 0 {
+		return
+	}
+	if err = db.Count(&n).Error; err != nil {
+		return
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+	return ErrStaleFence
+}
@@ -0,0 +1,65 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	klog "github.com/go-kratos/kratos/v2/log"
+)
+
+type account struct {
+	ID      int64
+	Balance int64
+	Fence   int64
+}
+
+func TestUpdatesWithFence(t *testing.T) {
+	db := New(t.Name(), sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), WithLogger(klog.DefaultLogger))
+	if db.Err != nil {
+		t.Fatalf("open sqlite: %+v", db.Err)
+	}
+	dbs := News(db, db)
+	c := context.Background()
+	if err := dbs.Write(c).AutoMigrate(&account{}); err != nil {
+		t.Fatalf("AutoMigrate: %+v", err)
+	}
+	dbs.Write(c).Create(&account{ID: 1})
+
+	update := func(id, fence, balance int64) error {
+		return UpdatesWithFence(
+			dbs.Write(c).Model(&account{}).Where("id = ?", id),
+			"fence", fence, map[string]interface{}{"balance": balance},
+		)
+	}
+
+	if err := update(1, 5, 100); err != nil {
+		t.Fatalf("UpdatesWithFence: %+v", err)
+	}
+	// 持有旧token的写入被拒绝,数据保持不变
+	if err := update(1, 4, 200); err != ErrStaleFence {
+		t.Fatalf("stale fence: %+v", err)
+	}
+	var a account
+	dbs.Read(c).First(&a, 1)
+	if a.Balance != 100 || a.Fence != 5 {
+		t.Errorf("account:\t%+v", a)
+	}
+	// 同一持有者在租约内可多次写入,写入相同的值也不算过期
+	for _, balance := range []int64{200, 200} {
+		if err := update(1, 5, balance); err != nil {
+			t.Fatalf("same fence: %+v", err)
+		}
+	}
+	dbs.Read(c).First(&a, 1)
+	if a.Balance != 200 || a.Fence != 5 {
+		t.Errorf("account:\t%+v", a)
+	}
+
+	if err := update(1, 6, 300); err != nil {
+		t.Fatalf("newer fence: %+v", err)
+	}
+	if err := update(2, 7, 300); err != ErrNoRecord {
+		t.Fatalf("missing record: %+v", err)
+	}
+}
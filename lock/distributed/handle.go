@@ -0,0 +1,118 @@
+package lock
+
+/*
+ * @abstract 分布式锁的持有句柄,带自动续期(看门狗)
+ */
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 每个过期周期内续期的次数
+const renewTimes = 3
+
+// Renewer 续期,只有code一致时才延长过期时间,否则返回ErrNotHeld.
+type Renewer interface {
+	Renew(c context.Context, key, code string, expire time.Duration) (err error)
+}
+
+// RenewableLock 支持续期的分布式锁,长耗时任务需要看门狗时使用
+type RenewableLock interface {
+	DistributedLock
+	Renewer
+}
+
+type Handle struct {
+	locker DistributedLock
+	key    string
+	code   string
+
+	lost   chan struct{}
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+// Obtain 抢锁并返回持有句柄.
+// 若locker实现了Renewer,后台会每expire/3续期一次,直到UnLock或c被取消;
+// 续期失败(锁已被他人持有,或剩余时间不足一个续期周期)时关闭Lost().
+func Obtain(c context.Context, locker DistributedLock, key string, expire, wait time.Duration) (h *Handle, err error) {
+	var code string
+	if code, err = locker.Lock(c, key, expire, wait); err != nil {
+		return
+	}
+
+	h = &Handle{
+		locker: locker,
+		key:    key,
+		code:   code,
+		lost:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	r, ok := locker.(Renewer)
+	if !ok {
+		h.cancel = func() {}
+		close(h.done)
+		return
+	}
+
+	var ctx context.Context
+	ctx, h.cancel = context.WithCancel(c)
+	go h.watchdog(ctx, r, expire)
+	return
+}
+
+func (h *Handle) Key() string {
+	return h.key
+}
+
+func (h *Handle) Code() string {
+	return h.code
+}
+
+// Lost 续期失败时关闭,调用方应尽快中止受保护的操作.
+func (h *Handle) Lost() <-chan struct{} {
+	return h.lost
+}
+
+// UnLock 停止续期并解锁.
+func (h *Handle) UnLock(c context.Context) (err error) {
+	h.cancel()
+	<-h.done
+	return h.locker.UnLock(c, h.key, h.code)
+}
+
+func (h *Handle) watchdog(c context.Context, r Renewer, expire time.Duration) {
+	defer close(h.done)
+
+	tick := expire / renewTimes
+	if tick <= 0 {
+		tick = time.Millisecond
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(expire)
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		}
+
+		begin := time.Now()
+		err := r.Renew(c, h.key, h.code, expire)
+		switch {
+		case err == nil:
+			deadline = begin.Add(expire)
+		case c.Err() != nil:
+			return
+		// 等到deadline之后锁可能已被他人持有,剩余时间不够再续一次就视为失去
+		case errors.Is(err, ErrNotHeld), time.Until(deadline) < tick:
+			close(h.lost)
+			return
+		}
+	}
+}
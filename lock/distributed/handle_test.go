@@ -0,0 +1,68 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+	"github.com/neo532/kratos_kit/lock/distributed/memory"
+)
+
+// unreachable 续期一直失败,模拟与存储之间网络中断
+type unreachable struct {
+	*memLock
+}
+
+func (l *unreachable) Renew(c context.Context, key, code string, expire time.Duration) error {
+	return errors.New("network is unreachable")
+}
+
+func TestHandleRenew(t *testing.T) {
+	l := memory.New()
+	c := context.Background()
+
+	h, err := lock.Obtain(c, l, "job", 30*time.Millisecond, 0)
+	if err != nil {
+		t.Fatalf("Obtain: %+v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err = l.Lock(c, "job", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock while renewed: %+v", err)
+	}
+	select {
+	case <-h.Lost():
+		t.Fatal("lost while renewed")
+	default:
+	}
+
+	if err = h.UnLock(c); err != nil {
+		t.Fatalf("UnLock: %+v", err)
+	}
+	if _, err = l.Lock(c, "job", time.Second, 0); err != nil {
+		t.Fatalf("Lock after UnLock: %+v", err)
+	}
+}
+
+// 续期持续失败时须在锁过期之前关闭Lost
+func TestHandleLostBeforeExpire(t *testing.T) {
+	l := &unreachable{memLock: memory.New()}
+	expire := 300 * time.Millisecond
+
+	begin := time.Now()
+	h, err := lock.Obtain(context.Background(), l, "job", expire, 0)
+	if err != nil {
+		t.Fatalf("Obtain: %+v", err)
+	}
+	defer h.UnLock(context.Background())
+
+	select {
+	case <-h.Lost():
+		if d := time.Since(begin); d >= expire {
+			t.Errorf("lost after the lease expired: %s", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not lost while renew keeps failing")
+	}
+}
@@ -8,10 +8,95 @@ package lock
 
 import (
 	"context"
+	"errors"
+	"math/rand"
 	"time"
+
+	"github.com/neo532/kratos_kit/middleware/tracing"
+)
+
+var (
+	ErrTimeout = errors.New("lock: wait timeout")
+	ErrNotHeld = errors.New("lock: not held")
+	ErrExpire  = errors.New("lock: expire must be positive")
+	ErrLimit   = errors.New("lock: limit must be positive")
+)
+
+const (
+	// 默认的key命名空间
+	DefaultNamespace = "lock"
+
+	// 轮询的最大间隔为初始间隔的倍数
+	maxIntervalTimes = 8
 )
 
 type DistributedLock interface {
 	Lock(c context.Context, key string, expire, wait time.Duration) (code string, err error)
 	UnLock(c context.Context, key string, code string) (err error)
 }
+
+// FencingLock 加锁的同时返回单调递增的fencing token,
+// 写存储时带上token,存储层即可拒绝锁已过期的旧持有者的写入.
+type FencingLock interface {
+	DistributedLock
+	LockWithFence(c context.Context, key string, expire, wait time.Duration) (code string, fence int64, err error)
+}
+
+// RWLock 读写锁,读锁之间共享,写锁与任何锁互斥.
+type RWLock interface {
+	DistributedLock
+	RLock(c context.Context, key string, expire, wait time.Duration) (code string, err error)
+	RUnLock(c context.Context, key string, code string) (err error)
+}
+
+// Retry 在wait时间内反复调用fn直到成功,间隔从interval开始指数退避并加入随机抖动.
+// wait<=0时只尝试一次.
+func Retry(c context.Context, wait, interval time.Duration, fn func() (ok bool, err error)) (err error) {
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	var ok bool
+	maxInterval := interval * maxIntervalTimes
+	endTs := time.Now().Add(wait)
+	for {
+		if ok, err = fn(); err != nil || ok {
+			return
+		}
+
+		left := time.Until(endTs)
+		if left <= 0 {
+			return ErrTimeout
+		}
+
+		sleep := interval/2 + time.Duration(rand.Int63n(int64(interval/2)+1))
+		if sleep > left {
+			sleep = left
+		}
+		timer := time.NewTimer(sleep)
+		select {
+		case <-c.Done():
+			timer.Stop()
+			return c.Err()
+		case <-timer.C:
+		}
+
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// Semaphore 分布式信号量,同一key同时最多有limit个持有者.
+type Semaphore interface {
+	Acquire(c context.Context, key string, limit int64, expire, wait time.Duration) (code string, err error)
+	Release(c context.Context, key string, code string) (err error)
+}
+
+// Key 生成带命名空间的key,压测流量(traceID以pts_开头)的命名空间追加_shadow,
+// 即使与线上共用同一个集群也不会争抢同一把锁.
+func Key(c context.Context, namespace, key string) string {
+	if namespace = tracing.UpdateNameByBenchmark(c, namespace); namespace == "" {
+		return key
+	}
+	return namespace + ":" + key
+}
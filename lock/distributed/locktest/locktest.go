@@ -0,0 +1,267 @@
+package locktest
+
+/*
+ * @abstract 分布式锁的一致性测试,所有DistributedLock的实现都应通过
+ */
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+	"github.com/neo532/kratos_kit/middleware/tracing"
+)
+
+// ========== Option ==========
+type option struct {
+	sleep func(d time.Duration)
+}
+type Opt func(*option)
+
+// WithSleep 让时间流逝d,默认time.Sleep.
+// 若后端的过期不随真实时间推进(如miniredis),需在此同步推进其时钟.
+func WithSleep(fn func(d time.Duration)) Opt {
+	return func(o *option) {
+		o.sleep = fn
+	}
+}
+
+// ========== /Option ==========
+
+// Run 对l执行一致性测试,若l实现了Renewer/FencingLock,一并测试.
+func Run(t *testing.T, l lock.DistributedLock, opts ...Opt) {
+	o := &option{
+		sleep: time.Sleep,
+	}
+	for _, fn := range opts {
+		fn(o)
+	}
+	c := context.Background()
+
+	t.Run("LockUnLock", func(t *testing.T) {
+		key := t.Name()
+		code, err := l.Lock(c, key, time.Second, 0)
+		if err != nil || code == "" {
+			t.Fatalf("Lock: code:%q, err:%+v", code, err)
+		}
+		if err = l.UnLock(c, key, code); err != nil {
+			t.Fatalf("UnLock: %+v", err)
+		}
+		if err = l.UnLock(c, key, code); !errors.Is(err, lock.ErrNotHeld) {
+			t.Fatalf("UnLock twice: %+v", err)
+		}
+	})
+
+	t.Run("Exclusive", func(t *testing.T) {
+		key := t.Name()
+		code, err := l.Lock(c, key, time.Second, 0)
+		if err != nil {
+			t.Fatalf("Lock: %+v", err)
+		}
+		defer l.UnLock(c, key, code)
+
+		if _, err = l.Lock(c, key, time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+			t.Fatalf("Lock held key: %+v", err)
+		}
+		if _, err = l.Lock(c, key, time.Second, 100*time.Millisecond); !errors.Is(err, lock.ErrTimeout) {
+			t.Fatalf("Lock held key with wait: %+v", err)
+		}
+	})
+
+	t.Run("WrongCode", func(t *testing.T) {
+		key := t.Name()
+		code, err := l.Lock(c, key, time.Second, 0)
+		if err != nil {
+			t.Fatalf("Lock: %+v", err)
+		}
+		defer l.UnLock(c, key, code)
+
+		if err = l.UnLock(c, key, code+"x"); !errors.Is(err, lock.ErrNotHeld) {
+			t.Fatalf("UnLock with wrong code: %+v", err)
+		}
+		if _, err = l.Lock(c, key, time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+			t.Fatalf("Lock after wrong UnLock: %+v", err)
+		}
+	})
+
+	t.Run("WaitForRelease", func(t *testing.T) {
+		key := t.Name()
+		code, err := l.Lock(c, key, time.Second, 0)
+		if err != nil {
+			t.Fatalf("Lock: %+v", err)
+		}
+		go func() {
+			o.sleep(100 * time.Millisecond)
+			l.UnLock(c, key, code)
+		}()
+
+		var next string
+		if next, err = l.Lock(c, key, time.Second, 2*time.Second); err != nil {
+			t.Fatalf("Lock after release: %+v", err)
+		}
+		l.UnLock(c, key, next)
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		key := t.Name()
+		code, err := l.Lock(c, key, 100*time.Millisecond, 0)
+		if err != nil {
+			t.Fatalf("Lock: %+v", err)
+		}
+		o.sleep(200 * time.Millisecond)
+
+		var next string
+		if next, err = l.Lock(c, key, time.Second, 0); err != nil {
+			t.Fatalf("Lock after expire: %+v", err)
+		}
+		defer l.UnLock(c, key, next)
+
+		if err = l.UnLock(c, key, code); !errors.Is(err, lock.ErrNotHeld) {
+			t.Fatalf("UnLock expired code: %+v", err)
+		}
+	})
+
+	t.Run("InvalidExpire", func(t *testing.T) {
+		if _, err := l.Lock(c, t.Name(), 0, 0); !errors.Is(err, lock.ErrExpire) {
+			t.Fatalf("Lock with zero expire: %+v", err)
+		}
+	})
+
+	t.Run("ContextCancel", func(t *testing.T) {
+		key := t.Name()
+		code, err := l.Lock(c, key, time.Second, 0)
+		if err != nil {
+			t.Fatalf("Lock: %+v", err)
+		}
+		defer l.UnLock(c, key, code)
+
+		ctx, cancel := context.WithTimeout(c, 100*time.Millisecond)
+		defer cancel()
+		if _, err = l.Lock(ctx, key, time.Second, 5*time.Second); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Lock with canceled context: %+v", err)
+		}
+	})
+
+	t.Run("Benchmark", func(t *testing.T) {
+		key := t.Name()
+		code, err := l.Lock(c, key, time.Second, 0)
+		if err != nil {
+			t.Fatalf("Lock: %+v", err)
+		}
+		defer l.UnLock(c, key, code)
+
+		bc := tracing.SetTraceIDForServer(c, "pts_"+key)
+		var bcode string
+		if bcode, err = l.Lock(bc, key, time.Second, 0); err != nil {
+			t.Fatalf("Lock with benchmark context: %+v", err)
+		}
+		if err = l.UnLock(c, key, bcode); !errors.Is(err, lock.ErrNotHeld) {
+			t.Fatalf("UnLock benchmark code without benchmark context: %+v", err)
+		}
+		if err = l.UnLock(bc, key, bcode); err != nil {
+			t.Fatalf("UnLock with benchmark context: %+v", err)
+		}
+	})
+
+	if r, ok := l.(lock.Renewer); ok {
+		runRenewer(t, o, l, r)
+	}
+	if f, ok := l.(lock.FencingLock); ok {
+		runFencing(t, f)
+	}
+}
+
+func runRenewer(t *testing.T, o *option, l lock.DistributedLock, r lock.Renewer) {
+	c := context.Background()
+
+	t.Run("Renew", func(t *testing.T) {
+		key := t.Name()
+		code, err := l.Lock(c, key, 200*time.Millisecond, 0)
+		if err != nil {
+			t.Fatalf("Lock: %+v", err)
+		}
+		if err = r.Renew(c, key, code+"x", time.Second); !errors.Is(err, lock.ErrNotHeld) {
+			t.Fatalf("Renew with wrong code: %+v", err)
+		}
+		if err = r.Renew(c, key, code, time.Second); err != nil {
+			t.Fatalf("Renew: %+v", err)
+		}
+		o.sleep(300 * time.Millisecond)
+
+		if _, err = l.Lock(c, key, time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+			t.Fatalf("Lock renewed key: %+v", err)
+		}
+		if err = l.UnLock(c, key, code); err != nil {
+			t.Fatalf("UnLock renewed key: %+v", err)
+		}
+	})
+
+	t.Run("Watchdog", func(t *testing.T) {
+		key := t.Name()
+		h, err := lock.Obtain(c, l, key, 150*time.Millisecond, 0)
+		if err != nil {
+			t.Fatalf("Obtain: %+v", err)
+		}
+		o.sleep(400 * time.Millisecond)
+
+		select {
+		case <-h.Lost():
+			t.Fatalf("Lost while renewing")
+		default:
+		}
+		if _, err = l.Lock(c, key, time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+			t.Fatalf("Lock watched key: %+v", err)
+		}
+		if err = h.UnLock(c); err != nil {
+			t.Fatalf("Handle UnLock: %+v", err)
+		}
+	})
+
+	t.Run("WatchdogLost", func(t *testing.T) {
+		key := t.Name()
+		h, err := lock.Obtain(c, l, key, 150*time.Millisecond, 0)
+		if err != nil {
+			t.Fatalf("Obtain: %+v", err)
+		}
+		defer h.UnLock(c)
+
+		if err = l.UnLock(c, key, h.Code()); err != nil {
+			t.Fatalf("UnLock: %+v", err)
+		}
+
+		var lost int32
+		go func() {
+			<-h.Lost()
+			atomic.StoreInt32(&lost, 1)
+		}()
+		o.sleep(200 * time.Millisecond)
+		if atomic.LoadInt32(&lost) == 0 {
+			t.Fatalf("Lost is not closed after the lock was released")
+		}
+	})
+}
+
+func runFencing(t *testing.T, l lock.FencingLock) {
+	c := context.Background()
+
+	t.Run("Fence", func(t *testing.T) {
+		key := t.Name()
+		var last int64
+		for i := 0; i < 3; i++ {
+			code, fence, err := l.LockWithFence(c, key, time.Second, 0)
+			if err != nil {
+				t.Fatalf("LockWithFence: %+v", err)
+			}
+			if fence <= last {
+				t.Fatalf("fence is not increasing: %d after %d", fence, last)
+			}
+			last = fence
+			if err = l.UnLock(c, key, code); err != nil {
+				t.Fatalf("UnLock: %+v", err)
+			}
+		}
+	})
+}
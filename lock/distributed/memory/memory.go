@@ -0,0 +1,176 @@
+package memory
+
+/*
+ * @abstract 进程内的分布式锁实现,用于单元测试和单进程运行
+ */
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+)
+
+// ========== Option ==========
+type Opt func(*Lock)
+
+// WithInterval 抢锁失败后首次重试的间隔,之后指数退避
+func WithInterval(d time.Duration) Opt {
+	return func(l *Lock) {
+		l.interval = d
+	}
+}
+func WithGenCode(fn func() (code string, err error)) Opt {
+	return func(l *Lock) {
+		l.genCode = fn
+	}
+}
+
+// WithNamespace key的命名空间,默认为lock
+func WithNamespace(ns string) Opt {
+	return func(l *Lock) {
+		l.namespace = ns
+	}
+}
+
+// ========== /Option ==========
+
+// 锁的数量超过上次清理后的2倍(至少minSweep)时清理一次过期的锁,均摊O(1)
+const minSweep = 64
+
+type entry struct {
+	code     string
+	expireAt time.Time
+}
+
+// ========== Lock ==========
+// Lock 与Redis版本语义一致:过期自动释放,按code解锁/续期,支持fencing token.
+type Lock struct {
+	mu     sync.Mutex
+	locks  map[string]entry
+	fences map[string]int64
+	// sweepAt locks达到该数量时清理过期的锁
+	sweepAt int
+
+	namespace string
+	interval  time.Duration
+	genCode   func() (code string, err error)
+}
+
+func New(opts ...Opt) (l *Lock) {
+	l = &Lock{
+		locks:     make(map[string]entry),
+		fences:    make(map[string]int64),
+		sweepAt:   minSweep,
+		namespace: lock.DefaultNamespace,
+		interval:  50 * time.Millisecond,
+		genCode: func() (code string, err error) {
+			code = uuid.NewV4().String()
+			return
+		},
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return
+}
+
+func (l *Lock) Lock(c context.Context, key string, expire, wait time.Duration) (code string, err error) {
+	code, _, err = l.acquire(c, key, expire, wait, false)
+	return
+}
+
+func (l *Lock) LockWithFence(c context.Context, key string, expire, wait time.Duration) (code string, fence int64, err error) {
+	return l.acquire(c, key, expire, wait, true)
+}
+
+func (l *Lock) UnLock(c context.Context, key string, code string) (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key = lock.Key(c, l.namespace, key)
+	if !l.held(key, code) {
+		return lock.ErrNotHeld
+	}
+	delete(l.locks, key)
+	return
+}
+
+func (l *Lock) Renew(c context.Context, key, code string, expire time.Duration) (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key = lock.Key(c, l.namespace, key)
+	if !l.held(key, code) {
+		return lock.ErrNotHeld
+	}
+	l.locks[key] = entry{code: code, expireAt: time.Now().Add(expire)}
+	return
+}
+
+func (l *Lock) acquire(c context.Context, key string, expire, wait time.Duration, fencing bool) (code string, fence int64, err error) {
+	if expire <= 0 {
+		err = lock.ErrExpire
+		return
+	}
+	if code, err = l.genCode(); err != nil {
+		return
+	}
+	key = lock.Key(c, l.namespace, key)
+
+	err = lock.Retry(c, wait, l.interval, func() (ok bool, err error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if e, has := l.locks[key]; has && time.Now().Before(e.expireAt) {
+			return
+		}
+		l.locks[key] = entry{code: code, expireAt: time.Now().Add(expire)}
+		l.sweep()
+		if fencing {
+			l.fences[key]++
+			fence = l.fences[key]
+		}
+		ok = true
+		return
+	})
+	if err != nil {
+		code = ""
+		fence = 0
+	}
+	return
+}
+
+// held 需在持有mu时调用,顺带清理已过期的锁
+func (l *Lock) held(key, code string) bool {
+	e, has := l.locks[key]
+	if !has {
+		return false
+	}
+	if !time.Now().Before(e.expireAt) {
+		delete(l.locks, key)
+		return false
+	}
+	return e.code == code
+}
+
+// sweep 需在持有mu时调用,按key加锁的场景(如订单号)不会只增不减
+func (l *Lock) sweep() {
+	if len(l.locks) < l.sweepAt {
+		return
+	}
+	now := time.Now()
+	for key, e := range l.locks {
+		if !now.Before(e.expireAt) {
+			delete(l.locks, key)
+		}
+	}
+	if l.sweepAt = 2 * len(l.locks); l.sweepAt < minSweep {
+		l.sweepAt = minSweep
+	}
+}
+
+// ========== /Lock ==========
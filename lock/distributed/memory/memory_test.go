@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/neo532/kratos_kit/lock/distributed/locktest"
+)
+
+func TestLock(t *testing.T) {
+	locktest.Run(t, New())
+}
+
+// 按不同key加锁时过期的锁会被清理
+func TestSweep(t *testing.T) {
+	l := New()
+	c := context.Background()
+	for i := 0; i < 10*minSweep; i++ {
+		if _, err := l.Lock(c, fmt.Sprintf("order:%d", i), time.Millisecond, 0); err != nil {
+			t.Fatalf("Lock %d: %+v", i, err)
+		}
+		if i%minSweep == 0 {
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+	if n := len(l.locks); n > 2*minSweep {
+		t.Errorf("expired locks are not swept: %d", n)
+	}
+}
@@ -0,0 +1,203 @@
+package orm
+
+/*
+ * @abstract 用数据库实现分布式锁
+ */
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/neo532/kratos_kit/database/orm"
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+)
+
+var ErrDialect = errors.New("lock: unsupported database dialect, use WithNowExpr")
+
+// nowExprs 各数据库取当前毫秒时间戳的SQL表达式,
+// 过期时间的写入和比较都在SQL中以数据库时钟计算,不受各服务器时钟偏差影响.
+var nowExprs = map[string]string{
+	"mysql":     "CAST(UNIX_TIMESTAMP(NOW(3)) * 1000 AS SIGNED)",
+	"postgres":  "CAST(EXTRACT(EPOCH FROM clock_timestamp()) * 1000 AS BIGINT)",
+	"sqlite":    "CAST((julianday('now') - 2440587.5) * 86400000 AS INTEGER)",
+	"sqlserver": "DATEDIFF_BIG(millisecond, '1970-01-01', SYSUTCDATETIME())",
+}
+
+// Model 锁表结构,expires_at为数据库时钟的毫秒时间戳
+type Model struct {
+	LockKey   string `gorm:"column:lock_key;primaryKey;size:191"`
+	Code      string `gorm:"column:code;size:64;not null"`
+	ExpiresAt int64  `gorm:"column:expires_at;not null"`
+}
+
+// ========== Option ==========
+type Opt func(*Lock)
+
+// WithInterval 抢锁失败后首次重试的间隔,之后指数退避
+func WithInterval(d time.Duration) Opt {
+	return func(l *Lock) {
+		l.interval = d
+	}
+}
+func WithGenCode(fn func() (code string, err error)) Opt {
+	return func(l *Lock) {
+		l.genCode = fn
+	}
+}
+
+// WithNamespace key的命名空间,默认为lock
+func WithNamespace(ns string) Opt {
+	return func(l *Lock) {
+		l.namespace = ns
+	}
+}
+func WithTable(name string) Opt {
+	return func(l *Lock) {
+		l.table = name
+	}
+}
+
+// WithNowExpr 取数据库当前毫秒时间戳的SQL表达式,内置mysql/postgres/sqlite/sqlserver
+func WithNowExpr(expr string) Opt {
+	return func(l *Lock) {
+		l.nowExpr = expr
+	}
+}
+
+// ========== /Option ==========
+
+// ========== Lock ==========
+// Lock 基于锁表的分布式锁,过期时间以数据库时钟为准.
+type Lock struct {
+	dbs       *orm.Orms
+	table     string
+	nowExpr   string
+	namespace string
+	interval  time.Duration
+	genCode   func() (code string, err error)
+}
+
+func New(dbs *orm.Orms, opts ...Opt) (l *Lock) {
+	l = &Lock{
+		dbs:       dbs,
+		table:     "distributed_lock",
+		namespace: lock.DefaultNamespace,
+		interval:  50 * time.Millisecond,
+		genCode: func() (code string, err error) {
+			code = uuid.NewV4().String()
+			return
+		},
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return
+}
+
+// AutoMigrate 创建或更新锁表
+func (l *Lock) AutoMigrate(c context.Context) (err error) {
+	return l.db(c).AutoMigrate(&Model{})
+}
+
+// Lock 抢锁:先插入,主键冲突时再尝试接管已过期的记录,两步均为原子操作.
+func (l *Lock) Lock(c context.Context, key string, expire, wait time.Duration) (code string, err error) {
+	if expire <= 0 {
+		err = lock.ErrExpire
+		return
+	}
+	if code, err = l.genCode(); err != nil {
+		return
+	}
+	key = lock.Key(c, l.namespace, key)
+
+	var now string
+	if now, err = l.now(c); err != nil {
+		code = ""
+		return
+	}
+	expireAt := gorm.Expr(now+" + ?", expire.Milliseconds())
+
+	err = lock.Retry(c, wait, l.interval, func() (ok bool, err error) {
+		tx := l.db(c).Clauses(clause.OnConflict{DoNothing: true}).Create(map[string]interface{}{
+			"lock_key":   key,
+			"code":       code,
+			"expires_at": expireAt,
+		})
+		if err = tx.Error; err != nil || tx.RowsAffected == 1 {
+			ok = err == nil
+			return
+		}
+
+		tx = l.db(c).
+			Where("lock_key = ? AND expires_at <= "+now, key).
+			Updates(map[string]interface{}{
+				"code":       code,
+				"expires_at": expireAt,
+			})
+		if err = tx.Error; err != nil {
+			return
+		}
+		ok = tx.RowsAffected == 1
+		return
+	})
+	if err != nil {
+		code = ""
+	}
+	return
+}
+
+func (l *Lock) UnLock(c context.Context, key string, code string) (err error) {
+	var now string
+	if now, err = l.now(c); err != nil {
+		return
+	}
+	tx := l.db(c).
+		Where("lock_key = ? AND code = ? AND expires_at > "+now, lock.Key(c, l.namespace, key), code).
+		Delete(&Model{})
+	if err = tx.Error; err != nil {
+		return
+	}
+	if tx.RowsAffected == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+func (l *Lock) Renew(c context.Context, key, code string, expire time.Duration) (err error) {
+	var now string
+	if now, err = l.now(c); err != nil {
+		return
+	}
+	tx := l.db(c).
+		Where("lock_key = ? AND code = ? AND expires_at > "+now, lock.Key(c, l.namespace, key), code).
+		Update("expires_at", gorm.Expr(now+" + ?", expire.Milliseconds()))
+	if err = tx.Error; err != nil {
+		return
+	}
+	if tx.RowsAffected == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+func (l *Lock) now(c context.Context) (expr string, err error) {
+	if l.nowExpr != "" {
+		return l.nowExpr, nil
+	}
+	var ok bool
+	if expr, ok = nowExprs[l.dbs.Write(c).Dialector.Name()]; !ok {
+		err = ErrDialect
+	}
+	return
+}
+
+func (l *Lock) db(c context.Context) *gorm.DB {
+	return l.dbs.Write(c).WithContext(c).Table(l.table)
+}
+
+// ========== /Lock ==========
@@ -0,0 +1,85 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	klog "github.com/go-kratos/kratos/v2/log"
+
+	"github.com/neo532/kratos_kit/database/orm"
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+	"github.com/neo532/kratos_kit/lock/distributed/locktest"
+)
+
+func TestLock(t *testing.T) {
+	db := orm.New(t.Name(), sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), orm.WithLogger(klog.DefaultLogger))
+	if db.Err != nil {
+		t.Fatalf("open sqlite: %+v", db.Err)
+	}
+	l := New(orm.News(db, db).SetShadow(db, db))
+	if err := l.AutoMigrate(context.Background()); err != nil {
+		t.Fatalf("AutoMigrate: %+v", err)
+	}
+	locktest.Run(t, l)
+}
+
+// 过期时间由数据库时钟计算
+func TestDatabaseClock(t *testing.T) {
+	db := orm.New(t.Name(), sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), orm.WithLogger(klog.DefaultLogger))
+	if db.Err != nil {
+		t.Fatalf("open sqlite: %+v", db.Err)
+	}
+	c := context.Background()
+	l := New(orm.News(db, db))
+	if err := l.AutoMigrate(c); err != nil {
+		t.Fatalf("AutoMigrate: %+v", err)
+	}
+	if _, err := l.Lock(c, "job", time.Minute, 0); err != nil {
+		t.Fatalf("Lock: %+v", err)
+	}
+
+	var left int64
+	l.db(c).Select("expires_at - "+nowExprs["sqlite"]).Where("lock_key = ?", "lock:job").Scan(&left)
+	if left <= 59000 || left > 60000 {
+		t.Errorf("expires_at - now:\t%d", left)
+	}
+}
+
+// 过期记录被新的持有者接管,原持有者不能再解锁或续期
+func TestTakeover(t *testing.T) {
+	db := orm.New(t.Name(), sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), orm.WithLogger(klog.DefaultLogger))
+	if db.Err != nil {
+		t.Fatalf("open sqlite: %+v", db.Err)
+	}
+	c := context.Background()
+	l := New(orm.News(db, db))
+	if err := l.AutoMigrate(c); err != nil {
+		t.Fatalf("AutoMigrate: %+v", err)
+	}
+
+	old, err := l.Lock(c, "job", time.Minute, 0)
+	if err != nil {
+		t.Fatalf("Lock: %+v", err)
+	}
+	if _, err = l.Lock(c, "job", time.Minute, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock while held: %+v", err)
+	}
+
+	l.db(c).Where("lock_key = ?", "lock:job").Update("expires_at", 0)
+	code, err := l.Lock(c, "job", time.Minute, 0)
+	if err != nil || code == old {
+		t.Fatalf("Lock after expired: %s, %+v", code, err)
+	}
+	if err = l.Renew(c, "job", old, time.Minute); !errors.Is(err, lock.ErrNotHeld) {
+		t.Errorf("Renew by old holder: %+v", err)
+	}
+	if err = l.UnLock(c, "job", old); !errors.Is(err, lock.ErrNotHeld) {
+		t.Errorf("UnLock by old holder: %+v", err)
+	}
+	if err = l.UnLock(c, "job", code); err != nil {
+		t.Errorf("UnLock: %+v", err)
+	}
+}
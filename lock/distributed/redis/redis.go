@@ -8,10 +8,47 @@ package redis
 
 import (
 	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
 
 	"github.com/neo532/kratos_kit/database/redis"
+	lock "github.com/neo532/kratos_kit/lock/distributed"
 )
 
+// KEYS[1]:key ARGV[1]:code ARGV[2]:expire(ms)
+const lockScript = `
+if redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2], 'NX') then
+	return 1
+end
+return 0
+`
+
+// KEYS[1]:key KEYS[2]:fence counter ARGV[1]:code ARGV[2]:expire(ms)
+// 计数器不设过期,保证token单调递增
+const fenceLockScript = `
+if redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2], 'NX') then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`
+
+// KEYS[1]:key ARGV[1]:code
+const unlockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// KEYS[1]:key ARGV[1]:code ARGV[2]:expire(ms)
+const renewScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
 // ========== GoRedis ==========
 type GoRedis struct {
 	Rdb *redis.Rediss
@@ -26,3 +63,119 @@ func (l *GoRedis) Get(c context.Context, key string) (rst string, err error) {
 }
 
 // ========== /GoRedis ==========
+
+// ========== Option ==========
+type Opt func(*Lock)
+
+// WithInterval 抢锁失败后首次重试的间隔,之后指数退避
+func WithInterval(d time.Duration) Opt {
+	return func(l *Lock) {
+		l.interval = d
+	}
+}
+func WithGenCode(fn func() (code string, err error)) Opt {
+	return func(l *Lock) {
+		l.genCode = fn
+	}
+}
+
+// WithNamespace key的命名空间,默认为lock
+func WithNamespace(ns string) Opt {
+	return func(l *Lock) {
+		l.namespace = ns
+	}
+}
+
+// ========== /Option ==========
+
+// ========== Lock ==========
+type Lock struct {
+	db        *GoRedis
+	namespace string
+	interval  time.Duration
+	genCode   func() (code string, err error)
+}
+
+func New(rdb *redis.Rediss, opts ...Opt) (l *Lock) {
+	l = &Lock{
+		db:        &GoRedis{Rdb: rdb},
+		namespace: lock.DefaultNamespace,
+		interval:  50 * time.Millisecond,
+		genCode: func() (code string, err error) {
+			code = uuid.NewV4().String()
+			return
+		},
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return
+}
+
+// Lock 抢锁,成功返回本次持有的code,用于解锁.
+func (l *Lock) Lock(c context.Context, key string, expire, wait time.Duration) (code string, err error) {
+	code, _, err = l.acquire(c, lockScript, []string{l.key(c, key)}, expire, wait)
+	return
+}
+
+// LockWithFence 抢锁,同时返回该key单调递增的fencing token.
+func (l *Lock) LockWithFence(c context.Context, key string, expire, wait time.Duration) (code string, fence int64, err error) {
+	key = l.key(c, key)
+	return l.acquire(c, fenceLockScript, []string{key, key + ":fence"}, expire, wait)
+}
+
+// UnLock 只有code一致时才会删除,防止误删他人的锁.
+func (l *Lock) UnLock(c context.Context, key string, code string) (err error) {
+	var rst interface{}
+	if rst, err = l.db.Eval(c, unlockScript, []string{l.key(c, key)}, []interface{}{code}); err != nil {
+		return
+	}
+	if n, _ := rst.(int64); n == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+// Renew 续期,只有code一致时才会延长过期时间.
+func (l *Lock) Renew(c context.Context, key, code string, expire time.Duration) (err error) {
+	var rst interface{}
+	if rst, err = l.db.Eval(c, renewScript, []string{l.key(c, key)}, []interface{}{code, expire.Milliseconds()}); err != nil {
+		return
+	}
+	if n, _ := rst.(int64); n == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+// acquire 执行加锁脚本直到返回值大于0,返回值即脚本的结果.
+func (l *Lock) acquire(c context.Context, script string, keys []string, expire, wait time.Duration) (code string, rst int64, err error) {
+	if expire <= 0 {
+		err = lock.ErrExpire
+		return
+	}
+	if code, err = l.genCode(); err != nil {
+		return
+	}
+
+	err = lock.Retry(c, wait, l.interval, func() (ok bool, err error) {
+		var r interface{}
+		if r, err = l.db.Eval(c, script, keys, []interface{}{code, expire.Milliseconds()}); err != nil {
+			return
+		}
+		rst, _ = r.(int64)
+		ok = rst > 0
+		return
+	})
+	if err != nil {
+		code = ""
+		rst = 0
+	}
+	return
+}
+
+func (l *Lock) key(c context.Context, key string) string {
+	return lock.Key(c, l.namespace, key)
+}
+
+// ========== /Lock ==========
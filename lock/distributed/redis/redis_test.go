@@ -0,0 +1,314 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/neo532/kratos_kit/database/redis"
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+	"github.com/neo532/kratos_kit/lock/distributed/locktest"
+	"github.com/neo532/kratos_kit/middleware/tracing"
+)
+
+// sleep miniredis的过期不随真实时间推进,需同步快进
+func sleep(ss ...*miniredis.Miniredis) locktest.Opt {
+	return locktest.WithSleep(func(d time.Duration) {
+		step := 10 * time.Millisecond
+		for ; d > 0; d -= step {
+			time.Sleep(step)
+			for _, s := range ss {
+				s.FastForward(step)
+			}
+		}
+	})
+}
+
+func TestLock(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	rdb := redis.News(r).SetShadow(r)
+	locktest.Run(t, New(rdb), sleep(s))
+}
+
+func TestLockOwner(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	l := New(redis.News(r))
+	c := context.Background()
+
+	code, err := l.Lock(c, "order", time.Second, 0)
+	if err != nil {
+		t.Fatalf("Lock: %+v", err)
+	}
+	if _, err = l.Lock(c, "order", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock while held: %+v", err)
+	}
+	if err = l.UnLock(c, "order", "other"); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("UnLock with another code: %+v", err)
+	}
+	if err = l.Renew(c, "order", "other", time.Second); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("Renew with another code: %+v", err)
+	}
+
+	s.FastForward(2 * time.Second)
+	if err = l.UnLock(c, "order", code); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("UnLock after expire: %+v", err)
+	}
+	if _, err = l.Lock(c, "order", time.Second, 0); err != nil {
+		t.Fatalf("Lock after expire: %+v", err)
+	}
+}
+
+func TestReentrant(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	rdb := redis.News(r).SetShadow(r)
+	locktest.Run(t, NewReentrant(rdb), sleep(s))
+}
+
+func TestReentrantOwner(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	l := NewReentrant(redis.News(r))
+	c1 := tracing.SetTraceIDForServer(context.Background(), "trace-1")
+	c2 := tracing.SetTraceIDForServer(context.Background(), "trace-2")
+
+	code, err := l.Lock(c1, "order", time.Second, 0)
+	if err != nil {
+		t.Fatalf("Lock: %+v", err)
+	}
+	if again, err := l.Lock(c1, "order", time.Second, 0); err != nil || again != code {
+		t.Fatalf("Lock again with the same trace:\t%s,%+v", again, err)
+	}
+	if _, err = l.Lock(c2, "order", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock with another trace: %+v", err)
+	}
+
+	// 加锁两次需要释放两次
+	if err = l.UnLock(c1, "order", code); err != nil {
+		t.Fatalf("UnLock: %+v", err)
+	}
+	if _, err = l.Lock(c2, "order", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock with another trace after one UnLock: %+v", err)
+	}
+	if err = l.UnLock(c1, "order", code); err != nil {
+		t.Fatalf("UnLock again: %+v", err)
+	}
+	if _, err = l.Lock(c2, "order", time.Second, 0); err != nil {
+		t.Fatalf("Lock with another trace after release: %+v", err)
+	}
+}
+
+// 内层较短的expire不会缩短外层的锁
+func TestReentrantExpire(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	l := NewReentrant(redis.News(r))
+	c1 := tracing.SetTraceIDForServer(context.Background(), "trace-1")
+	c2 := tracing.SetTraceIDForServer(context.Background(), "trace-2")
+
+	if _, err := l.Lock(c1, "order", 10*time.Second, 0); err != nil {
+		t.Fatalf("Lock: %+v", err)
+	}
+	if _, err := l.Lock(c1, "order", 100*time.Millisecond, 0); err != nil {
+		t.Fatalf("Lock again: %+v", err)
+	}
+	s.FastForward(time.Second)
+	if _, err := l.Lock(c2, "order", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock with another trace after the inner expire: %+v", err)
+	}
+}
+
+func TestRWLockShare(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	l := NewRWLock(redis.News(r))
+	c := context.Background()
+
+	r1, err := l.RLock(c, "doc", time.Second, 0)
+	if err != nil {
+		t.Fatalf("RLock: %+v", err)
+	}
+	r2, err := l.RLock(c, "doc", time.Second, 0)
+	if err != nil {
+		t.Fatalf("RLock shared: %+v", err)
+	}
+	if _, err = l.Lock(c, "doc", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock while reading: %+v", err)
+	}
+
+	l.RUnLock(c, "doc", r1)
+	if _, err = l.Lock(c, "doc", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock while one reader left: %+v", err)
+	}
+	l.RUnLock(c, "doc", r2)
+
+	w, err := l.Lock(c, "doc", time.Second, 0)
+	if err != nil {
+		t.Fatalf("Lock after readers left: %+v", err)
+	}
+	if _, err = l.RLock(c, "doc", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("RLock while writing: %+v", err)
+	}
+	if _, err = l.Lock(c, "doc", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock while writing: %+v", err)
+	}
+	if err = l.UnLock(c, "doc", w); err != nil {
+		t.Fatalf("UnLock: %+v", err)
+	}
+	if _, err = l.RLock(c, "doc", time.Second, 0); err != nil {
+		t.Fatalf("RLock after writer left: %+v", err)
+	}
+}
+
+// 读者过期按Redis服务端时间判断
+func TestRWLockReaderExpire(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	l := NewRWLock(redis.News(r))
+	c := context.Background()
+
+	now := time.Now()
+	s.SetTime(now)
+	if _, err := l.RLock(c, "doc", time.Second, 0); err != nil {
+		t.Fatalf("RLock: %+v", err)
+	}
+	if _, err := l.Lock(c, "doc", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock while reading: %+v", err)
+	}
+	s.SetTime(now.Add(2 * time.Second))
+	if _, err := l.Lock(c, "doc", time.Second, 0); err != nil {
+		t.Fatalf("Lock after the reader expired: %+v", err)
+	}
+}
+
+func TestRWLock(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	rdb := redis.News(r).SetShadow(r)
+	locktest.Run(t, NewRWLock(rdb), sleep(s))
+}
+
+func TestRedlock(t *testing.T) {
+	l, ss := newRedlock(t)
+	locktest.Run(t, l, sleep(ss...))
+}
+
+func newRedlock(t *testing.T) (l *Redlock, ss []*miniredis.Miniredis) {
+	ss = []*miniredis.Miniredis{miniredis.RunT(t), miniredis.RunT(t), miniredis.RunT(t)}
+	rdbs := make([]*redis.Redis, 0, len(ss))
+	for i, s := range ss {
+		rdbs = append(rdbs, redis.New(t.Name()+string(rune('0'+i)), s.Addr()))
+	}
+	l = NewRedlock(rdbs)
+	return
+}
+
+// 少数节点宕机不影响加锁
+func TestRedlockNodeDown(t *testing.T) {
+	l, ss := newRedlock(t)
+	c := context.Background()
+	ss[2].Close()
+
+	code, err := l.Lock(c, "order", time.Second, 0)
+	if err != nil {
+		t.Fatalf("Lock with one node down: %+v", err)
+	}
+	if err = l.Renew(c, "order", code, time.Second); err != nil {
+		t.Fatalf("Renew with one node down: %+v", err)
+	}
+	if err = l.UnLock(c, "order", code); err != nil {
+		t.Fatalf("UnLock with one node down: %+v", err)
+	}
+}
+
+// 多数节点已被他人持有时加锁失败,并回滚已加锁的少数节点
+func TestRedlockMinority(t *testing.T) {
+	l, ss := newRedlock(t)
+	c := context.Background()
+	for _, s := range ss[:2] {
+		s.Set("lock:order", "other")
+	}
+
+	if _, err := l.Lock(c, "order", time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Lock held on 2 of 3 nodes: %+v", err)
+	}
+	if ss[2].Exists("lock:order") {
+		t.Errorf("the minority node is not rolled back")
+	}
+	for _, s := range ss[:2] {
+		if v, _ := s.Get("lock:order"); v != "other" {
+			t.Errorf("the other holder is released: %s", v)
+		}
+	}
+}
+
+// 续期成功的节点不足半数时返回ErrNotHeld
+func TestRedlockRenewQuorum(t *testing.T) {
+	l, ss := newRedlock(t)
+	c := context.Background()
+
+	code, err := l.Lock(c, "order", time.Second, 0)
+	if err != nil {
+		t.Fatalf("Lock: %+v", err)
+	}
+	ss[0].Del("lock:order")
+	if err = l.Renew(c, "order", code, time.Second); err != nil {
+		t.Fatalf("Renew on 2 of 3 nodes: %+v", err)
+	}
+	ss[1].Del("lock:order")
+	if err = l.Renew(c, "order", code, time.Second); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("Renew on 1 of 3 nodes: %+v", err)
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := redis.New(t.Name(), s.Addr())
+	sem := NewSemaphore(redis.News(r))
+	c := context.Background()
+	now := time.Now()
+	s.SetTime(now)
+
+	codes := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		code, err := sem.Acquire(c, "export", 3, time.Second, 0)
+		if err != nil {
+			t.Fatalf("Acquire %d: %+v", i, err)
+		}
+		codes = append(codes, code)
+	}
+	if _, err := sem.Acquire(c, "export", 3, time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Acquire over limit: %+v", err)
+	}
+	if err := sem.Release(c, "export", codes[0]); err != nil {
+		t.Fatalf("Release: %+v", err)
+	}
+	if err := sem.Release(c, "export", codes[0]); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("Release twice: %+v", err)
+	}
+	if _, err := sem.Acquire(c, "export", 3, time.Second, 0); err != nil {
+		t.Fatalf("Acquire after release: %+v", err)
+	}
+	if err := sem.Renew(c, "export", codes[1], 3*time.Second); err != nil {
+		t.Fatalf("Renew: %+v", err)
+	}
+
+	// 未续期的名额按Redis服务端时间过期后自动让出
+	s.SetTime(now.Add(1100 * time.Millisecond))
+	for i := 0; i < 2; i++ {
+		if _, err := sem.Acquire(c, "export", 3, time.Second, 0); err != nil {
+			t.Fatalf("Acquire %d after expire: %+v", i, err)
+		}
+	}
+	if _, err := sem.Acquire(c, "export", 3, time.Second, 0); !errors.Is(err, lock.ErrTimeout) {
+		t.Fatalf("Acquire over limit after expire: %+v", err)
+	}
+	if err := sem.Release(c, "export", codes[2]); !errors.Is(err, lock.ErrNotHeld) {
+		t.Fatalf("Release an expired one: %+v", err)
+	}
+}
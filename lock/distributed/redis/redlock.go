@@ -0,0 +1,164 @@
+package redis
+
+/*
+ * @abstract 用多个独立的Redis实例实现分布式锁(Redlock算法)
+ */
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/neo532/kratos_kit/database/redis"
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+)
+
+// ========== RedlockOption ==========
+type RedlockOpt func(*Redlock)
+
+// WithRedlockInterval 抢锁失败后首次重试的间隔,之后指数退避
+func WithRedlockInterval(d time.Duration) RedlockOpt {
+	return func(l *Redlock) {
+		l.interval = d
+	}
+}
+func WithRedlockGenCode(fn func() (code string, err error)) RedlockOpt {
+	return func(l *Redlock) {
+		l.genCode = fn
+	}
+}
+
+// WithRedlockNamespace key的命名空间,默认为lock
+func WithRedlockNamespace(ns string) RedlockOpt {
+	return func(l *Redlock) {
+		l.namespace = ns
+	}
+}
+
+// WithDriftFactor 时钟漂移系数,有效期会扣除expire*factor+2ms
+func WithDriftFactor(f float64) RedlockOpt {
+	return func(l *Redlock) {
+		l.driftFactor = f
+	}
+}
+
+// WithNodeTimeout 单个节点的请求超时,应远小于expire
+func WithNodeTimeout(d time.Duration) RedlockOpt {
+	return func(l *Redlock) {
+		l.nodeTimeout = d
+	}
+}
+
+// ========== /RedlockOption ==========
+
+// ========== Redlock ==========
+// Redlock 在N个独立的Redis主节点上加锁,超过半数成功且仍在有效期内才算成功.
+type Redlock struct {
+	rdbs        []*redis.Redis
+	quorum      int
+	namespace   string
+	interval    time.Duration
+	driftFactor float64
+	nodeTimeout time.Duration
+	genCode     func() (code string, err error)
+}
+
+func NewRedlock(rdbs []*redis.Redis, opts ...RedlockOpt) (l *Redlock) {
+	l = &Redlock{
+		rdbs:        rdbs,
+		quorum:      len(rdbs)/2 + 1,
+		namespace:   lock.DefaultNamespace,
+		interval:    50 * time.Millisecond,
+		driftFactor: 0.01,
+		nodeTimeout: 50 * time.Millisecond,
+		genCode: func() (code string, err error) {
+			code = uuid.NewV4().String()
+			return
+		},
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return
+}
+
+func (l *Redlock) Lock(c context.Context, key string, expire, wait time.Duration) (code string, err error) {
+	code, _, err = l.LockWithValidity(c, key, expire, wait)
+	return
+}
+
+// LockWithValidity 抢锁,同时返回扣除加锁耗时和时钟漂移后锁的剩余有效期.
+func (l *Redlock) LockWithValidity(c context.Context, key string, expire, wait time.Duration) (code string, validity time.Duration, err error) {
+	if expire <= 0 {
+		err = lock.ErrExpire
+		return
+	}
+	if code, err = l.genCode(); err != nil {
+		return
+	}
+	key = l.key(c, key)
+
+	err = lock.Retry(c, wait, l.interval, func() (ok bool, err error) {
+		begin := time.Now()
+		n := l.eval(c, lockScript, key, code, expire.Milliseconds())
+
+		drift := time.Duration(float64(expire)*l.driftFactor) + 2*time.Millisecond
+		validity = expire - time.Since(begin) - drift
+		if n >= l.quorum && validity > 0 {
+			ok = true
+			return
+		}
+		l.eval(c, unlockScript, key, code)
+		return
+	})
+	if err != nil {
+		code = ""
+		validity = 0
+	}
+	return
+}
+
+// UnLock 在所有节点上解锁,任一节点删除成功即视为成功.
+func (l *Redlock) UnLock(c context.Context, key string, code string) (err error) {
+	if n := l.eval(c, unlockScript, l.key(c, key), code); n == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+// Renew 在所有节点上续期,超过半数成功才算成功.
+func (l *Redlock) Renew(c context.Context, key, code string, expire time.Duration) (err error) {
+	if n := l.eval(c, renewScript, l.key(c, key), code, expire.Milliseconds()); n < l.quorum {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+// eval 并发在所有节点上执行脚本,返回结果为1的节点数,节点错误视为失败.
+func (l *Redlock) eval(c context.Context, script, key string, args ...interface{}) int {
+	var wg sync.WaitGroup
+	var n int32
+	for _, rdb := range l.rdbs {
+		wg.Add(1)
+		go func(rdb *redis.Redis) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(c, l.nodeTimeout)
+			defer cancel()
+			if rst, err := rdb.Client.Eval(ctx, script, []string{key}, args...).Int64(); err == nil && rst == 1 {
+				atomic.AddInt32(&n, 1)
+			}
+		}(rdb)
+	}
+	wg.Wait()
+	return int(n)
+}
+
+func (l *Redlock) key(c context.Context, key string) string {
+	return lock.Key(c, l.namespace, key)
+}
+
+// ========== /Redlock ==========
@@ -0,0 +1,109 @@
+package redis
+
+/*
+ * @abstract 用Redis实现可重入分布式锁,同一链路(traceID)可重复加锁
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/neo532/kratos_kit/database/redis"
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+	"github.com/neo532/kratos_kit/middleware/tracing"
+)
+
+// KEYS[1]:key ARGV[1]:owner ARGV[2]:expire(ms)
+const reentrantLockScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 or redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 then
+	redis.call('HINCRBY', KEYS[1], ARGV[1], 1)
+	-- 重入只延长不缩短,避免内层较短的expire提前释放外层的锁
+	if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+		redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	end
+	return 1
+end
+return 0
+`
+
+// KEYS[1]:key ARGV[1]:owner
+const reentrantUnlockScript = `
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return -1
+end
+if redis.call('HINCRBY', KEYS[1], ARGV[1], -1) > 0 then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+return 1
+`
+
+// KEYS[1]:key ARGV[1]:owner ARGV[2]:expire(ms)
+const reentrantRenewScript = `
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+// ========== Reentrant ==========
+// Reentrant 可重入锁,持有者为context中的traceID,没有traceID时每次生成新的code(即不可重入).
+// 每次Lock都需要对应一次UnLock,计数归零时才真正释放.
+type Reentrant struct {
+	l *Lock
+}
+
+func NewReentrant(rdb *redis.Rediss, opts ...Opt) *Reentrant {
+	return &Reentrant{l: New(rdb, opts...)}
+}
+
+func (l *Reentrant) Lock(c context.Context, key string, expire, wait time.Duration) (code string, err error) {
+	if expire <= 0 {
+		err = lock.ErrExpire
+		return
+	}
+	if code = tracing.GetTraceIDByCtx(c); code == "" {
+		if code, err = l.l.genCode(); err != nil {
+			return
+		}
+	}
+	key = l.l.key(c, key)
+
+	err = lock.Retry(c, wait, l.l.interval, func() (ok bool, err error) {
+		var rst interface{}
+		if rst, err = l.l.db.Eval(c, reentrantLockScript, []string{key}, []interface{}{code, expire.Milliseconds()}); err != nil {
+			return
+		}
+		n, _ := rst.(int64)
+		ok = n == 1
+		return
+	})
+	if err != nil {
+		code = ""
+	}
+	return
+}
+
+func (l *Reentrant) UnLock(c context.Context, key string, code string) (err error) {
+	var rst interface{}
+	if rst, err = l.l.db.Eval(c, reentrantUnlockScript, []string{l.l.key(c, key)}, []interface{}{code}); err != nil {
+		return
+	}
+	if n, _ := rst.(int64); n == -1 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+func (l *Reentrant) Renew(c context.Context, key, code string, expire time.Duration) (err error) {
+	var rst interface{}
+	if rst, err = l.l.db.Eval(c, reentrantRenewScript, []string{l.l.key(c, key)}, []interface{}{code, expire.Milliseconds()}); err != nil {
+		return
+	}
+	if n, _ := rst.(int64); n == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+// ========== /Reentrant ==========
@@ -0,0 +1,189 @@
+package redis
+
+/*
+ * @abstract 用Redis实现分布式读写锁
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/neo532/kratos_kit/database/redis"
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+)
+
+// 数据结构为hash:
+// 写锁: mode=write, owner=code
+// 读锁: mode=read, 每个读者 code=过期时间戳(ms)
+
+// nowScript 读者的过期时间取Redis服务端时间(ms),不依赖各实例的时钟.
+// Redis 5之前脚本中调用TIME后再写入需要先开启命令复制.
+const nowScript = `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+`
+
+// 清理已过期的读者
+const purgeReadersScript = `
+local fields = redis.call('HGETALL', KEYS[1])
+for i = 1, #fields, 2 do
+	if fields[i] ~= 'mode' and tonumber(fields[i + 1]) <= now then
+		redis.call('HDEL', KEYS[1], fields[i])
+	end
+end
+`
+
+// KEYS[1]:key ARGV[1]:code ARGV[2]:expire(ms)
+const rLockScript = nowScript + `
+local mode = redis.call('HGET', KEYS[1], 'mode')
+if mode == 'write' then
+	return 0
+end
+if mode == 'read' then
+` + purgeReadersScript + `
+end
+redis.call('HSET', KEYS[1], 'mode', 'read', ARGV[1], now + tonumber(ARGV[2]))
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`
+
+// KEYS[1]:key ARGV[1]:code
+const rUnlockScript = `
+if redis.call('HGET', KEYS[1], 'mode') ~= 'read' or redis.call('HDEL', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+if redis.call('HLEN', KEYS[1]) == 1 then
+	redis.call('DEL', KEYS[1])
+end
+return 1
+`
+
+// KEYS[1]:key ARGV[1]:code ARGV[2]:expire(ms)
+const wLockScript = nowScript + `
+local mode = redis.call('HGET', KEYS[1], 'mode')
+if mode == 'read' then
+` + purgeReadersScript + `
+	if redis.call('HLEN', KEYS[1]) > 1 then
+		return 0
+	end
+	redis.call('DEL', KEYS[1])
+elseif mode then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'mode', 'write', 'owner', ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`
+
+// KEYS[1]:key ARGV[1]:code
+const wUnlockScript = `
+if redis.call('HGET', KEYS[1], 'mode') == 'write' and redis.call('HGET', KEYS[1], 'owner') == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// KEYS[1]:key ARGV[1]:code ARGV[2]:expire(ms)
+const rwRenewScript = nowScript + `
+local mode = redis.call('HGET', KEYS[1], 'mode')
+if mode == 'write' then
+	if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+		return 0
+	end
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+if mode ~= 'read' then
+	return 0
+end
+local at = redis.call('HGET', KEYS[1], ARGV[1])
+if not at or tonumber(at) <= now then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], now + tonumber(ARGV[2]))
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`
+
+// ========== RWLock ==========
+// RWLock 读写锁,code同时用于Renew,读锁和写锁都可续期.
+type RWLock struct {
+	l *Lock
+}
+
+func NewRWLock(rdb *redis.Rediss, opts ...Opt) *RWLock {
+	return &RWLock{l: New(rdb, opts...)}
+}
+
+// RLock 加读锁,与其他读锁共享.
+func (l *RWLock) RLock(c context.Context, key string, expire, wait time.Duration) (code string, err error) {
+	return l.acquire(c, rLockScript, key, expire, wait)
+}
+
+func (l *RWLock) RUnLock(c context.Context, key string, code string) (err error) {
+	return l.release(c, rUnlockScript, key, code)
+}
+
+// Lock 加写锁,与所有锁互斥.
+func (l *RWLock) Lock(c context.Context, key string, expire, wait time.Duration) (code string, err error) {
+	return l.acquire(c, wLockScript, key, expire, wait)
+}
+
+func (l *RWLock) UnLock(c context.Context, key string, code string) (err error) {
+	return l.release(c, wUnlockScript, key, code)
+}
+
+func (l *RWLock) Renew(c context.Context, key, code string, expire time.Duration) (err error) {
+	var rst interface{}
+	if rst, err = l.l.db.Eval(c, rwRenewScript, []string{l.l.key(c, key)}, []interface{}{code, expire.Milliseconds()}); err != nil {
+		return
+	}
+	if n, _ := rst.(int64); n == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+func (l *RWLock) acquire(c context.Context, script, key string, expire, wait time.Duration) (code string, err error) {
+	if expire <= 0 {
+		err = lock.ErrExpire
+		return
+	}
+	if code, err = l.l.genCode(); err != nil {
+		return
+	}
+	key = l.l.key(c, key)
+
+	err = lock.Retry(c, wait, l.l.interval, func() (ok bool, err error) {
+		var rst interface{}
+		if rst, err = l.l.db.Eval(c, script, []string{key}, []interface{}{code, expire.Milliseconds()}); err != nil {
+			return
+		}
+		n, _ := rst.(int64)
+		ok = n == 1
+		return
+	})
+	if err != nil {
+		code = ""
+	}
+	return
+}
+
+func (l *RWLock) release(c context.Context, script, key, code string) (err error) {
+	var rst interface{}
+	if rst, err = l.l.db.Eval(c, script, []string{l.l.key(c, key)}, []interface{}{code}); err != nil {
+		return
+	}
+	if n, _ := rst.(int64); n == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+// ========== /RWLock ==========
@@ -0,0 +1,124 @@
+package redis
+
+/*
+ * @abstract 用Redis实现分布式信号量,限制同一资源的并发持有者数量
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/neo532/kratos_kit/database/redis"
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+)
+
+// 数据结构为zset: member=code, score=过期时间戳(ms),时间取Redis服务端时间
+
+// KEYS[1]:key ARGV[1]:code ARGV[2]:limit ARGV[3]:expire(ms)
+const semAcquireScript = nowScript + `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[3]), ARGV[1])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`
+
+// KEYS[1]:key ARGV[1]:code
+const semReleaseScript = nowScript + `
+local at = redis.call('ZSCORE', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[1], ARGV[1])
+if not at or tonumber(at) <= now then
+	return 0
+end
+return 1
+`
+
+// KEYS[1]:key ARGV[1]:code ARGV[2]:expire(ms)
+const semRenewScript = nowScript + `
+local at = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not at or tonumber(at) <= now then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`
+
+// ========== Semaphore ==========
+// Semaphore 每个持有者独立过期,崩溃的持有者到期后自动让出名额.
+type Semaphore struct {
+	l *Lock
+}
+
+func NewSemaphore(rdb *redis.Rediss, opts ...Opt) *Semaphore {
+	return &Semaphore{l: New(rdb, opts...)}
+}
+
+// Acquire 获取一个名额,已有limit个持有者时在wait内等待.
+func (s *Semaphore) Acquire(c context.Context, key string, limit int64, expire, wait time.Duration) (code string, err error) {
+	if expire <= 0 {
+		err = lock.ErrExpire
+		return
+	}
+	if limit <= 0 {
+		err = lock.ErrLimit
+		return
+	}
+	if code, err = s.l.genCode(); err != nil {
+		return
+	}
+	key = s.l.key(c, key)
+
+	err = lock.Retry(c, wait, s.l.interval, func() (ok bool, err error) {
+		var rst interface{}
+		if rst, err = s.l.db.Eval(c, semAcquireScript, []string{key}, []interface{}{
+			code,
+			limit,
+			expire.Milliseconds(),
+		}); err != nil {
+			return
+		}
+		n, _ := rst.(int64)
+		ok = n == 1
+		return
+	})
+	if err != nil {
+		code = ""
+	}
+	return
+}
+
+// Release 归还名额,名额已过期时返回ErrNotHeld.
+func (s *Semaphore) Release(c context.Context, key string, code string) (err error) {
+	var rst interface{}
+	if rst, err = s.l.db.Eval(c, semReleaseScript, []string{s.l.key(c, key)}, []interface{}{code}); err != nil {
+		return
+	}
+	if n, _ := rst.(int64); n == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+// Renew 续期一个名额,可配合长耗时任务使用.
+func (s *Semaphore) Renew(c context.Context, key, code string, expire time.Duration) (err error) {
+	var rst interface{}
+	if rst, err = s.l.db.Eval(c, semRenewScript, []string{s.l.key(c, key)}, []interface{}{
+		code,
+		expire.Milliseconds(),
+	}); err != nil {
+		return
+	}
+	if n, _ := rst.(int64); n == 0 {
+		err = lock.ErrNotHeld
+	}
+	return
+}
+
+// ========== /Semaphore ==========
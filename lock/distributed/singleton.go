@@ -0,0 +1,177 @@
+package lock
+
+/*
+ * @abstract 单例任务执行器,多个实例中同一时刻只有一个在执行
+ */
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	klog "github.com/go-kratos/kratos/v2/log"
+
+	"github.com/neo532/kratos_kit/log"
+	"github.com/neo532/kratos_kit/middleware"
+	"github.com/neo532/kratos_kit/middleware/tracing"
+)
+
+var ErrLost = errors.New("lock: lease lost")
+
+// unlockTimeout 解锁不随c取消,单独限时
+const unlockTimeout = 3 * time.Second
+
+// detachKeys 解锁时沿用的context值,保证压测namespace、可重入owner和日志一致
+var detachKeys = []string{
+	middleware.Env,
+	middleware.Entry,
+	middleware.From,
+	middleware.Benchmark,
+	middleware.TraceID,
+	middleware.RPCID,
+	middleware.Group,
+}
+
+// ========== SingletonOption ==========
+type SingletonOpt func(*Singleton)
+
+// WithExpire 锁的过期时间,持有期间会自动续期
+func WithExpire(d time.Duration) SingletonOpt {
+	return func(s *Singleton) {
+		s.expire = d
+	}
+}
+
+// WithWait 抢锁的等待时间,默认不等待
+func WithWait(d time.Duration) SingletonOpt {
+	return func(s *Singleton) {
+		s.wait = d
+	}
+}
+
+// WithInterval Serve中两次Run之间的间隔
+func WithInterval(d time.Duration) SingletonOpt {
+	return func(s *Singleton) {
+		s.interval = d
+	}
+}
+func WithLogger(l klog.Logger) SingletonOpt {
+	return func(s *Singleton) {
+		s.logger = log.NewHelper(l)
+	}
+}
+
+// ========== /SingletonOption ==========
+
+// ========== Singleton ==========
+type Singleton struct {
+	locker RenewableLock
+	key    string
+	fn     func(c context.Context) error
+
+	expire   time.Duration
+	wait     time.Duration
+	interval time.Duration
+	logger   *log.Helper
+}
+
+// NewSingleton locker须支持续期,执行时间超过expire的任务才不会失去互斥
+func NewSingleton(locker RenewableLock, key string, fn func(c context.Context) error, opts ...SingletonOpt) (s *Singleton) {
+	s = &Singleton{
+		locker:   locker,
+		key:      key,
+		fn:       fn,
+		expire:   30 * time.Second,
+		interval: 10 * time.Second,
+		logger:   log.NewHelper(klog.DefaultLogger),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return
+}
+
+// Run 抢到锁则执行fn,否则直接返回nil.
+// 执行期间自动续期,续期失败会取消fn的context并返回ErrLost(fn自身报错时返回fn的错误).
+func (s *Singleton) Run(c context.Context) (err error) {
+	c = tracing.Script(c)
+
+	var h *Handle
+	if h, err = Obtain(c, s.locker, s.key, s.expire, s.wait); err != nil {
+		if errors.Is(err, ErrTimeout) {
+			s.logger.WithContext(c).Infof("Singleton[%s] is running elsewhere, skip.", s.key)
+			return nil
+		}
+		s.logger.WithContext(c).Errorf("Singleton[%s] lock has error: %+v", s.key, err)
+		return
+	}
+	s.logger.WithContext(c).Infof("Singleton[%s] acquired[code:%s].", s.key, h.Code())
+
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-h.Lost():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err = s.fn(ctx)
+	cancel()
+
+	// c在停机时已被取消,用它解锁会失败,锁要等到expire后才能被其他实例接管
+	uc, ucancel := context.WithTimeout(detach(c), unlockTimeout)
+	defer ucancel()
+	if e := h.UnLock(uc); e != nil && !errors.Is(e, ErrNotHeld) {
+		s.logger.WithContext(c).Errorf("Singleton[%s] unlock has error: %+v", s.key, e)
+	}
+
+	// UnLock返回时看门狗已退出,Lost()的状态不会再变
+	select {
+	case <-h.Lost():
+		s.logger.WithContext(c).Warnf("Singleton[%s] lost the lease, the job was canceled!", s.key)
+		if err == nil {
+			err = ErrLost
+		}
+	default:
+		s.logger.WithContext(c).Infof("Singleton[%s] released.", s.key)
+	}
+	return
+}
+
+// Serve 每隔interval尝试Run一次,持有者退出或失去锁后由其他实例接管,直到c被取消.
+func (s *Singleton) Serve(c context.Context) (err error) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		// ticker与c.Done同时就绪时select随机选择,先检查c避免退出后又抢一次锁
+		if err = c.Err(); err != nil {
+			return
+		}
+		if err = s.Run(c); err != nil {
+			s.logger.WithContext(c).Errorf("Singleton[%s] run has error: %+v", s.key, err)
+		}
+
+		select {
+		case <-c.Done():
+			return c.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// detach 复制c中的链路信息,但不继承c的取消和超时
+func detach(c context.Context) (ctx context.Context) {
+	ctx = context.Background()
+	for _, k := range detachKeys {
+		if v := c.Value(k); v != nil {
+			ctx = context.WithValue(ctx, k, v)
+		}
+	}
+	return
+}
+
+// ========== /Singleton ==========
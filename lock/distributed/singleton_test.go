@@ -0,0 +1,157 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	klog "github.com/go-kratos/kratos/v2/log"
+
+	lock "github.com/neo532/kratos_kit/lock/distributed"
+	"github.com/neo532/kratos_kit/lock/distributed/memory"
+	"github.com/neo532/kratos_kit/middleware/tracing"
+)
+
+type memLock = memory.Lock
+
+// stealable 续期失败,模拟锁被他人接管
+type stealable struct {
+	*memLock
+	stolen int32
+}
+
+func (l *stealable) Renew(c context.Context, key, code string, expire time.Duration) error {
+	if atomic.LoadInt32(&l.stolen) == 1 {
+		return lock.ErrNotHeld
+	}
+	return l.memLock.Renew(c, key, code, expire)
+}
+
+// ctxLock 与Redis、ORM实现一样,context被取消后解锁失败
+type ctxLock struct {
+	*memLock
+	trace chan string
+}
+
+func (l *ctxLock) UnLock(c context.Context, key, code string) error {
+	if err := c.Err(); err != nil {
+		return err
+	}
+	l.trace <- tracing.GetTraceIDByCtx(c)
+	return l.memLock.UnLock(c, key, code)
+}
+
+func TestSingletonSkip(t *testing.T) {
+	l := memory.New()
+	c := context.Background()
+	code, _ := l.Lock(c, "job", time.Minute, 0)
+
+	var ran bool
+	s := lock.NewSingleton(l, "job", func(c context.Context) error {
+		ran = true
+		return nil
+	}, lock.WithLogger(klog.DefaultLogger))
+	if err := s.Run(c); err != nil || ran {
+		t.Fatalf("Run while held elsewhere:\t%v,%+v", ran, err)
+	}
+
+	l.UnLock(c, "job", code)
+	if err := s.Run(c); err != nil || !ran {
+		t.Fatalf("Run after release:\t%v,%+v", ran, err)
+	}
+}
+
+func TestSingletonLost(t *testing.T) {
+	l := &stealable{memLock: memory.New()}
+	s := lock.NewSingleton(l, "job", func(c context.Context) error {
+		atomic.StoreInt32(&l.stolen, 1)
+		<-c.Done()
+		return nil
+	}, lock.WithExpire(30*time.Millisecond))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(context.Background())
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, lock.ErrLost) {
+			t.Fatalf("Run: %+v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the job is not canceled after the lease was lost")
+	}
+}
+
+func TestSingletonServe(t *testing.T) {
+	l := memory.New()
+	running := make(chan string, 2)
+	job := func(name string) func(c context.Context) error {
+		return func(c context.Context) error {
+			running <- name
+			<-c.Done()
+			return nil
+		}
+	}
+	opts := []lock.SingletonOpt{lock.WithExpire(30 * time.Millisecond), lock.WithInterval(10 * time.Millisecond)}
+
+	ca, cancelA := context.WithCancel(context.Background())
+	cb, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	go lock.NewSingleton(l, "job", job("a"), opts...).Serve(ca)
+	if name := <-running; name != "a" {
+		t.Fatalf("first runner: %s", name)
+	}
+	go lock.NewSingleton(l, "job", job("b"), opts...).Serve(cb)
+
+	// a持有期间b一直抢不到
+	select {
+	case name := <-running:
+		t.Fatalf("%s runs while a holds the lock", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancelA()
+	select {
+	case name := <-running:
+		if name != "b" {
+			t.Fatalf("takeover runner: %s", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("b does not take over after a exits")
+	}
+}
+
+// Serve被取消后立即释放锁,其他实例无需等待expire
+func TestSingletonShutdown(t *testing.T) {
+	l := &ctxLock{memLock: memory.New(), trace: make(chan string, 1)}
+	running := make(chan struct{})
+	s := lock.NewSingleton(l, "job", func(c context.Context) error {
+		close(running)
+		<-c.Done()
+		return nil
+	}, lock.WithExpire(time.Minute))
+
+	c, cancel := context.WithCancel(tracing.SetTraceIDForServer(context.Background(), "trace-1"))
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(c)
+	}()
+	<-running
+	cancel()
+	<-done
+
+	select {
+	case trace := <-l.trace:
+		if trace != "trace-1" {
+			t.Errorf("trace of UnLock: %s", trace)
+		}
+	default:
+		t.Error("the lock is not released after Serve exits")
+	}
+	if _, err := l.Lock(context.Background(), "job", time.Second, 0); err != nil {
+		t.Fatalf("Lock after Serve exits: %+v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package encrypt
+
+/*
+ * @abstract 请求体/响应体加密中间件
+ */
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	kmdw "github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/neo532/kratos_kit/crypt"
+)
+
+// errors.Reason
+const (
+	ReasonDecrypt = "DECRYPT"
+	ReasonEncrypt = "ENCRYPT"
+)
+
+// Header 请求头表示请求体为密文,响应头表示响应体为密文
+const Header = "X-Encrypt"
+
+// Matcher 按operation选择需要加解密的接口
+type Matcher func(operation string) bool
+
+// All 全部接口
+func All() Matcher {
+	return func(operation string) bool {
+		return true
+	}
+}
+
+// Operations 指定的接口
+func Operations(operations ...string) Matcher {
+	m := make(map[string]struct{}, len(operations))
+	for _, op := range operations {
+		m[op] = struct{}{}
+	}
+	return func(operation string) bool {
+		_, ok := m[operation]
+		return ok
+	}
+}
+
+type options struct {
+	match Matcher
+}
+
+type Opt func(o *options)
+
+// WithMatch 默认全部接口
+func WithMatch(m Matcher) Opt {
+	return func(o *options) {
+		o.match = m
+	}
+}
+
+// Server 按operation决定HTTP接口的加解密,reply的类型保持不变,生成代码和gRPC不受影响.
+// 解密和加密分别由transport/http.DecryptRequestDecoder、EncryptResponseEncoder完成:
+// 请求带Header时解码器解密请求体,匹配的接口请求未带Header时返回ReasonDecrypt;
+// 匹配的接口成功返回时在响应头设置Header,编码器据此加密Response.Data.
+// 解码发生在SetOperation之前,因此只有此处能按operation匹配,两个方向共用同一个Matcher.
+func Server(opts ...Opt) kmdw.Middleware {
+	o := &options{
+		match: All(),
+	}
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	return func(handler kmdw.Handler) kmdw.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || tr.Kind() != transport.KindHTTP || !o.match(tr.Operation()) {
+				return handler(ctx, req)
+			}
+			if tr.RequestHeader().Get(Header) == "" {
+				err = errors.BadRequest(ReasonDecrypt, "request body must be encrypted")
+				return
+			}
+			if reply, err = handler(ctx, req); err != nil {
+				return
+			}
+			tr.ReplyHeader().Set(Header, "1")
+			return
+		}
+	}
+}
+
+// Encrypt 序列化v并加密,错误转换为kratos errors
+func Encrypt(cr crypt.ICrypt, codec encoding.Codec, v interface{}) (en *wrapperspb.StringValue, err error) {
+	var b []byte
+	if b, err = codec.Marshal(v); err != nil {
+		err = errors.InternalServer(ReasonEncrypt, "marshal reply failed").WithCause(err)
+		return
+	}
+	var s string
+	if s, err = cr.Encrypt(b); err != nil {
+		err = errors.InternalServer(ReasonEncrypt, "encrypt reply failed").WithCause(err)
+		return
+	}
+	en = wrapperspb.String(s)
+	return
+}
+
+// Decrypt 解密请求体,错误转换为kratos errors
+func Decrypt(cr crypt.ICrypt, body []byte) (origin []byte, err error) {
+	if origin, err = cr.Decrypt(string(body)); err != nil {
+		err = errors.BadRequest(ReasonDecrypt, "decrypt request failed").WithCause(err)
+	}
+	return
+}
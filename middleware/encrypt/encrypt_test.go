@@ -0,0 +1,94 @@
+package encrypt
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	kmdw "github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/neo532/kratos_kit/crypt/openssl/gcm"
+)
+
+type header http.Header
+
+func (h header) Get(key string) string      { return http.Header(h).Get(key) }
+func (h header) Set(key, value string)      { http.Header(h).Set(key, value) }
+func (h header) Add(key, value string)      { http.Header(h).Add(key, value) }
+func (h header) Values(key string) []string { return http.Header(h).Values(key) }
+func (h header) Keys() (keys []string) {
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return
+}
+
+type testTransport struct {
+	transport.Transporter
+	kind      transport.Kind
+	operation string
+	req       header
+	reply     header
+}
+
+func (tr *testTransport) Kind() transport.Kind {
+	return tr.kind
+}
+func (tr *testTransport) Operation() string {
+	return tr.operation
+}
+func (tr *testTransport) RequestHeader() transport.Header {
+	return tr.req
+}
+func (tr *testTransport) ReplyHeader() transport.Header {
+	return tr.reply
+}
+
+// echoHandler 与protoc-gen-go-http/grpc生成的代码一样对reply做类型断言
+func echoHandler(m kmdw.Middleware, ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	h := m(func(c context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("echo:" + req.(*wrapperspb.StringValue).Value), nil
+	})
+	out, err := h(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out.(*wrapperspb.StringValue), nil
+}
+
+func TestServer(t *testing.T) {
+	m := Server(WithMatch(Operations("/test.Echo/Secret")))
+	newCtx := func(kind transport.Kind, operation string, req header) (context.Context, *testTransport) {
+		tr := &testTransport{kind: kind, operation: operation, req: req, reply: header{}}
+		return transport.NewServerContext(context.Background(), tr), tr
+	}
+
+	c, tr := newCtx(transport.KindHTTP, "/test.Echo/Secret", header{Header: {"1"}})
+	reply, err := echoHandler(m, c, wrapperspb.String("hello"))
+	if err != nil || reply.Value != "echo:hello" || tr.reply.Get(Header) == "" {
+		t.Errorf("matched:\t%+v,%+v,%+v", reply, tr.reply, err)
+	}
+
+	c, _ = newCtx(transport.KindHTTP, "/test.Echo/Secret", header{})
+	if _, err = echoHandler(m, c, wrapperspb.String("hello")); errors.Reason(err) != ReasonDecrypt {
+		t.Errorf("plaintext request: %+v", err)
+	}
+
+	c, tr = newCtx(transport.KindHTTP, "/test.Echo/Public", header{})
+	if reply, err = echoHandler(m, c, wrapperspb.String("hello")); err != nil || tr.reply.Get(Header) != "" {
+		t.Errorf("unmatched operation:\t%+v,%+v,%+v", reply, tr.reply, err)
+	}
+
+	c, tr = newCtx(transport.KindGRPC, "/test.Echo/Secret", header{})
+	if reply, err = echoHandler(m, c, wrapperspb.String("hello")); err != nil || reply.Value != "echo:hello" {
+		t.Errorf("grpc:\t%+v,%+v", reply, err)
+	}
+
+	cr := gcm.New(gcm.WithKey("rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"))
+	if _, err = Decrypt(cr, []byte("plain")); errors.Reason(err) != ReasonDecrypt {
+		t.Errorf("Decrypt: %+v", err)
+	}
+}
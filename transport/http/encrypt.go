@@ -0,0 +1,64 @@
+package http
+
+/*
+ * @abstract 传输协议http的请求体解密与响应体加密
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+
+	"github.com/neo532/kratos_kit/crypt"
+	"github.com/neo532/kratos_kit/middleware/encrypt"
+)
+
+// DecryptRequestDecoder 请求头带encrypt.Header时请求体为密文,解密后再按Content-Type反序列化.
+// 需配合encrypt.Server中间件,由它按operation检查必须加密的接口是否带了请求头
+func DecryptRequestDecoder(cr crypt.ICrypt) khttp.DecodeRequestFunc {
+	return func(r *http.Request, v interface{}) (err error) {
+		if r.Header.Get(encrypt.Header) == "" {
+			return khttp.DefaultRequestDecoder(r, v)
+		}
+
+		codec, ok := khttp.CodecForRequest(r, "Content-Type")
+		if !ok {
+			return errors.BadRequest("CODEC", fmt.Sprintf("unregister Content-Type: %s", r.Header.Get("Content-Type")))
+		}
+		var data []byte
+		if data, err = io.ReadAll(r.Body); err != nil {
+			return errors.BadRequest("CODEC", err.Error())
+		}
+		r.Body = io.NopCloser(bytes.NewBuffer(data))
+		if data = bytes.TrimSpace(data); len(data) == 0 {
+			return
+		}
+
+		if data, err = encrypt.Decrypt(cr, data); err != nil {
+			return
+		}
+		if err = codec.Unmarshal(data, v); err != nil {
+			return errors.BadRequest("CODEC", fmt.Sprintf("body unmarshal %s", err.Error()))
+		}
+		return
+	}
+}
+
+// EncryptResponseEncoder encrypt.Server在响应头设置了encrypt.Header时,把reply序列化后加密,
+// 再交给ResponseEncoder输出,密文位于Response.Data
+func EncryptResponseEncoder(cr crypt.ICrypt) khttp.EncodeResponseFunc {
+	return func(w http.ResponseWriter, r *http.Request, d interface{}) (err error) {
+		if w.Header().Get(encrypt.Header) != "" {
+			codec, _ := khttp.CodecForRequest(r, "Accept")
+			if d, err = encrypt.Encrypt(cr, codec, d); err != nil {
+				w.Header().Del(encrypt.Header)
+				return
+			}
+		}
+		return ResponseEncoder(w, r, d)
+	}
+}
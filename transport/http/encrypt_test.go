@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/neo532/kratos_kit/crypt/openssl/gcm"
+	"github.com/neo532/kratos_kit/middleware/encrypt"
+)
+
+// echoHandler 与protoc-gen-go-http生成的代码相同:先Bind再SetOperation,对reply做类型断言
+func echoHandler(operation string) khttp.HandlerFunc {
+	return func(ctx khttp.Context) error {
+		var in wrapperspb.StringValue
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		khttp.SetOperation(ctx, operation)
+		h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+			return wrapperspb.String("echo:" + req.(*wrapperspb.StringValue).Value), nil
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*wrapperspb.StringValue)
+		return ctx.Result(200, reply)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	cr := gcm.New(gcm.WithKey("rW@vM2UlXKGh2V%!7@%x5mjclBG40HGc"))
+	srv := khttp.NewServer(
+		khttp.Middleware(encrypt.Server(encrypt.WithMatch(encrypt.Operations("/test.Echo/Echo")))),
+		khttp.RequestDecoder(DecryptRequestDecoder(cr)),
+		khttp.ResponseEncoder(EncryptResponseEncoder(cr)),
+		khttp.ErrorEncoder(ErrorEncoder),
+	)
+	srv.Route("/").POST("/v1/echo", echoHandler("/test.Echo/Echo"))
+	srv.Route("/").POST("/v1/public", echoHandler("/test.Echo/Public"))
+	post := func(path, body string, encrypted bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if encrypted {
+			r.Header.Set(encrypt.Header, "1")
+		}
+		srv.ServeHTTP(w, r)
+		return w
+	}
+	var reply struct {
+		Code int32
+		Data struct {
+			Value string
+		}
+	}
+
+	body, _ := cr.Encrypt([]byte(`"hello"`))
+	w := post("/v1/echo", body, true)
+	if err := json.Unmarshal(w.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("Unmarshal %s: %+v", w.Body.String(), err)
+	}
+	origin, err := cr.Decrypt(reply.Data.Value)
+	if err != nil || string(origin) != `"echo:hello"` || w.Header().Get(encrypt.Header) == "" {
+		t.Errorf("reply:\t%s,%+v", w.Body.String(), err)
+	}
+
+	// 匹配的接口必须加密
+	for _, encrypted := range []bool{true, false} {
+		var se errors.Error
+		w = post("/v1/echo", `"hello"`, encrypted)
+		if json.Unmarshal(w.Body.Bytes(), &se); se.Reason != encrypt.ReasonDecrypt {
+			t.Errorf("plaintext body(%v):\t%s", encrypted, w.Body.String())
+		}
+	}
+
+	w = post("/v1/public", `"hello"`, false)
+	if json.Unmarshal(w.Body.Bytes(), &reply); reply.Data.Value != "echo:hello" || w.Header().Get(encrypt.Header) != "" {
+		t.Errorf("unmatched operation:\t%s", w.Body.String())
+	}
+}